@@ -16,4 +16,14 @@ variable "sddc_manager_host" {
 variable "vcf_cluster_id" {
   description = "Id of the cluster that is to be used as a data source. Note: management domain default cluster ID can be used to refer to some of it's attributes"
   default = ""
-}
\ No newline at end of file
+}
+
+variable "vcf_cluster_name" {
+  description = "Name of the cluster that is to be used as a data source"
+  default = ""
+}
+
+variable "vcf_domain_name" {
+  description = "Name of the domain of the cluster that is to be used as a data source"
+  default = ""
+}
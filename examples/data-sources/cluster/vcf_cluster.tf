@@ -14,4 +14,13 @@ provider "vcf" {
 
 data "vcf_cluster" "cluster1" {
   cluster_id = var.vcf_cluster_id
-}
\ No newline at end of file
+}
+
+data "vcf_cluster" "cluster2" {
+  name      = var.vcf_cluster_name
+  domain_id = data.vcf_domain.domain1.id
+}
+
+data "vcf_domain" "domain1" {
+  name = var.vcf_domain_name
+}
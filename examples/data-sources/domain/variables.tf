@@ -16,4 +16,9 @@ variable "sddc_manager_host" {
 variable "vcf_domain_id" {
   description = "Id of the domain that is to be used as a data source. Note: management domain ID can be used to refer to some of it's attributes"
   default = ""
-}
\ No newline at end of file
+}
+
+variable "vcf_domain_name" {
+  description = "Name of the domain that is to be used as a data source"
+  default = ""
+}
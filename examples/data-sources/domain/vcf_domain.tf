@@ -14,4 +14,8 @@ provider "vcf" {
 
 data "vcf_domain" "domain1" {
   domain_id = var.vcf_domain_id
-}
\ No newline at end of file
+}
+
+data "vcf_domain" "domain2" {
+  name = var.vcf_domain_name
+}
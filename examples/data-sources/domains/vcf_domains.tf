@@ -0,0 +1,22 @@
+terraform {
+  required_providers {
+    vcf = {
+      source  = "vmware/vcf"
+    }
+  }
+}
+
+provider "vcf" {
+  sddc_manager_username = var.sddc_manager_username
+  sddc_manager_password = var.sddc_manager_password
+  sddc_manager_host     = var.sddc_manager_host
+}
+
+data "vcf_domains" "workload_domains" {
+  type   = "VI"
+  status = "ACTIVE"
+}
+
+output "workload_domain_names" {
+  value = data.vcf_domains.workload_domains.domains[*].name
+}
\ No newline at end of file
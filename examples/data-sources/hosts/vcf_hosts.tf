@@ -0,0 +1,23 @@
+terraform {
+  required_providers {
+    vcf = {
+      source  = "vmware/vcf"
+    }
+  }
+}
+
+provider "vcf" {
+  sddc_manager_username = var.sddc_manager_username
+  sddc_manager_password = var.sddc_manager_password
+  sddc_manager_host     = var.sddc_manager_host
+}
+
+data "vcf_hosts" "free_hosts" {
+  network_pool_id = var.network_pool_id
+  status          = "UNASSIGNED_USEABLE"
+  storage_type    = "VSAN"
+}
+
+output "free_host_fqdns" {
+  value = data.vcf_hosts.free_hosts.hosts[*].fqdn
+}
\ No newline at end of file
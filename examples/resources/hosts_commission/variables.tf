@@ -0,0 +1,49 @@
+variable "sddc_manager_username" {
+  description = "Username used to authenticate against an SDDC Manager instance"
+  default = ""
+}
+
+variable "sddc_manager_password" {
+  description = "Password used to authenticate against an SDDC Manager instance"
+  default = ""
+}
+
+variable "sddc_manager_host" {
+  description = "Fully qualified domain name of an SDDC Manager instance"
+  default = ""
+}
+
+variable "network_pool_id" {
+  description = "Id of the network pool to associate the ESXi hosts with"
+  default = ""
+}
+
+variable "esx_host1_fqdn" {
+  description = "FQDN of an ESXi host that is to be commissioned"
+  default = ""
+}
+
+variable "esx_host1_pass" {
+  description = "Password to authenticate to the ESXi host"
+  default = ""
+}
+
+variable "esx_host2_fqdn" {
+  description = "FQDN of an ESXi host that is to be commissioned"
+  default = ""
+}
+
+variable "esx_host2_pass" {
+  description = "Password to authenticate to the ESXi host"
+  default = ""
+}
+
+variable "esx_host3_fqdn" {
+  description = "FQDN of an ESXi host that is to be commissioned"
+  default = ""
+}
+
+variable "esx_host3_pass" {
+  description = "Password to authenticate to the ESXi host"
+  default = ""
+}
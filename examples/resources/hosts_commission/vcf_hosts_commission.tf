@@ -0,0 +1,38 @@
+terraform {
+  required_providers {
+    vcf = {
+      source  = "vmware/vcf"
+    }
+  }
+}
+
+provider "vcf" {
+  sddc_manager_username = var.sddc_manager_username
+  sddc_manager_password = var.sddc_manager_password
+  sddc_manager_host     = var.sddc_manager_host
+}
+
+resource "vcf_hosts_commission" "hosts" {
+  host {
+    fqdn            = var.esx_host1_fqdn
+    username        = "root"
+    password        = var.esx_host1_pass
+    network_pool_id = var.network_pool_id
+    storage_type    = "VSAN"
+  }
+  host {
+    fqdn            = var.esx_host2_fqdn
+    username        = "root"
+    password        = var.esx_host2_pass
+    network_pool_id = var.network_pool_id
+    storage_type    = "VSAN"
+  }
+  host {
+    fqdn            = var.esx_host3_fqdn
+    username        = "root"
+    password        = var.esx_host3_pass
+    network_pool_id = var.network_pool_id
+    storage_type    = "VSAN"
+  }
+  deletion_protection = true
+}
\ No newline at end of file
@@ -0,0 +1,65 @@
+variable "sddc_manager_username" {
+  description = "Username used to authenticate against an SDDC Manager instance"
+  default = ""
+}
+
+variable "sddc_manager_password" {
+  description = "Password used to authenticate against an SDDC Manager instance"
+  default = ""
+}
+
+variable "sddc_manager_host" {
+  description = "Fully qualified domain name of an SDDC Manager instance"
+  default = ""
+}
+
+variable "esx_host1_fqdn" {
+  description = "FQDN of a commissioned ESXi host of the default cluster of the management domain"
+  default = ""
+}
+
+variable "esx_host1_pass" {
+  description = "Password to authenticate to the ESXi host"
+  default = ""
+}
+
+variable "esx_host2_fqdn" {
+  description = "FQDN of a commissioned ESXi host of the default cluster of the management domain"
+  default = ""
+}
+
+variable "esx_host2_pass" {
+  description = "Password to authenticate to the ESXi host"
+  default = ""
+}
+
+variable "esx_host3_fqdn" {
+  description = "FQDN of a commissioned ESXi host of the default cluster of the management domain"
+  default = ""
+}
+
+variable "esx_host3_pass" {
+  description = "Password to authenticate to the ESXi host"
+  default = ""
+}
+
+variable "esx_host4_fqdn" {
+  description = "FQDN of a commissioned ESXi host of the default cluster of the management domain"
+  default = ""
+}
+
+variable "esx_host4_pass" {
+  description = "Password to authenticate to the ESXi host"
+  default = ""
+}
+
+variable "esx_license_key" {
+  description = "License key for an ESXi host in the free pool. This is required except in cases where the " +
+  "ESXi host has already been licensed outside of the VMware Cloud Foundation system"
+  default = ""
+}
+
+variable "vsan_license_key" {
+  description = "vSAN license key to be used"
+  default = ""
+}
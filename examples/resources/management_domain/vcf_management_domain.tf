@@ -0,0 +1,67 @@
+terraform {
+  required_providers {
+    vcf = {
+      source  = "vmware/vcf"
+    }
+  }
+}
+
+provider "vcf" {
+  sddc_manager_username = var.sddc_manager_username
+  sddc_manager_password = var.sddc_manager_password
+  sddc_manager_host     = var.sddc_manager_host
+}
+
+// Adopts the management domain created during the bring-up, the domain is left in place when the resource is destroyed
+resource "vcf_management_domain" "management_domain" {
+  name = "sfo-m01"
+  // The existing default cluster is adopted, its hosts are changed to match the configuration
+  cluster {
+    name = "sfo-m01-cl01"
+    host {
+      fqdn = var.esx_host1_fqdn
+      license_key = var.esx_license_key
+      password = var.esx_host1_pass
+      password_version = "1"
+    }
+    host {
+      fqdn = var.esx_host2_fqdn
+      license_key = var.esx_license_key
+      password = var.esx_host2_pass
+      password_version = "1"
+    }
+    host {
+      fqdn = var.esx_host3_fqdn
+      license_key = var.esx_license_key
+      password = var.esx_host3_pass
+      password_version = "1"
+    }
+    host {
+      fqdn = var.esx_host4_fqdn
+      license_key = var.esx_license_key
+      password = var.esx_host4_pass
+      password_version = "1"
+    }
+    vds {
+      name = "sfo-m01-cl01-vds01"
+      portgroup {
+        name = "sfo-m01-cl01-vds01-pg-mgmt"
+        transport_type = "MANAGEMENT"
+      }
+      portgroup {
+        name = "sfo-m01-cl01-vds01-pg-vsan"
+        transport_type = "VSAN"
+      }
+      portgroup {
+        name = "sfo-m01-cl01-vds01-pg-vmotion"
+        transport_type = "VMOTION"
+      }
+    }
+    vsan_datastore {
+      datastore_name = "sfo-m01-cl01-ds-vsan01"
+      failures_to_tolerate = 1
+      license_key = var.vsan_license_key
+    }
+    geneve_vlan_id = 3
+  }
+}
\ No newline at end of file
@@ -0,0 +1,179 @@
+/*
+ *  Copyright 2023 VMware, Inc.
+ *    SPDX-License-Identifier: MPL-2.0
+ */
+
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"github.com/vmware/terraform-provider-vcf/internal/constants"
+	"github.com/vmware/terraform-provider-vcf/internal/datastores"
+	"github.com/vmware/terraform-provider-vcf/internal/resource_utils"
+	validationUtils "github.com/vmware/terraform-provider-vcf/internal/validation"
+	"github.com/vmware/vcf-sdk-go/client"
+	"github.com/vmware/vcf-sdk-go/client/clusters"
+	"github.com/vmware/vcf-sdk-go/models"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// supplementalDatastoreAttributes the datastore attributes of a cluster, whose datastores can be
+// mounted and unmounted after the creation of the cluster.
+var supplementalDatastoreAttributes = []string{"nfs_datastores", "vvol_datastores"}
+
+// CreateDatastoreMountChanges returns the specs of the NFS and vVol datastores that are added to an existing
+// cluster and the names of the datastores that are removed from it. Modifying a mounted datastore
+// is not supported, the principal datastore of the cluster can't be removed.
+func CreateDatastoreMountChanges(oldCluster, newCluster map[string]interface{}) ([]*models.DatastoreMountSpec, []string, error) {
+	var mountSpecs []*models.DatastoreMountSpec
+	var removedDatastoreNames []string
+	primaryDatastoreName, _ := oldCluster["primary_datastore_name"].(string)
+
+	for _, attributeName := range supplementalDatastoreAttributes {
+		oldValue, _ := oldCluster[attributeName].([]interface{})
+		newValue, _ := newCluster[attributeName].([]interface{})
+		oldDatastores := resource_utils.CreateKeyToObjectMap(oldValue, "datastore_name")
+		newDatastores := resource_utils.CreateKeyToObjectMap(newValue, "datastore_name")
+
+		for _, datastoreName := range sortedKeys(newDatastores) {
+			newDatastore := newDatastores[datastoreName]
+			if oldDatastore, exists := oldDatastores[datastoreName]; exists {
+				if err := validateMountedDatastoreUnchanged(datastoreName, oldDatastore, newDatastore); err != nil {
+					return nil, nil, err
+				}
+				continue
+			}
+			mountSpec, err := tryConvertToDatastoreMountSpec(attributeName, newDatastore)
+			if err != nil {
+				return nil, nil, err
+			}
+			mountSpecs = append(mountSpecs, mountSpec)
+		}
+
+		for _, datastoreName := range sortedKeys(oldDatastores) {
+			if _, exists := newDatastores[datastoreName]; exists {
+				continue
+			}
+			if datastoreName == primaryDatastoreName {
+				return nil, nil, fmt.Errorf("removing the principal datastore %q of the cluster is not supported",
+					datastoreName)
+			}
+			removedDatastoreNames = append(removedDatastoreNames, datastoreName)
+		}
+	}
+
+	return mountSpecs, removedDatastoreNames, nil
+}
+
+// CreateDatastoreMountChangesFromData is the CreateDatastoreMountChanges counterpart for the vcf_cluster resource.
+// The attributes of the cluster are read with the provided functions, so that the changes can be
+// checked both in the plan and in the apply.
+func CreateDatastoreMountChangesFromData(get func(string) interface{},
+	getChange func(string) (interface{}, interface{})) ([]*models.DatastoreMountSpec, []string, error) {
+	oldCluster := map[string]interface{}{
+		"primary_datastore_name": get("primary_datastore_name"),
+	}
+	newCluster := make(map[string]interface{})
+	for _, attributeName := range supplementalDatastoreAttributes {
+		oldCluster[attributeName], newCluster[attributeName] = getChange(attributeName)
+	}
+	return CreateDatastoreMountChanges(oldCluster, newCluster)
+}
+
+// SupplementalDatastoreAttributes returns the datastore attributes of a cluster, whose datastores can be
+// mounted and unmounted after the creation of the cluster.
+func SupplementalDatastoreAttributes() []string {
+	return supplementalDatastoreAttributes
+}
+
+// IsSupplementalDatastoreAttribute returns whether the datastores in the cluster attribute
+// can be mounted and unmounted after the creation of the cluster.
+func IsSupplementalDatastoreAttribute(attributeName string) bool {
+	for _, supplementalDatastoreAttribute := range supplementalDatastoreAttributes {
+		if attributeName == supplementalDatastoreAttribute {
+			return true
+		}
+	}
+	return false
+}
+
+// GetClusterDatastoreIdsByName returns the IDs of the datastores mounted to a cluster, indexed by name.
+func GetClusterDatastoreIdsByName(ctx context.Context, clusterId string, apiClient *client.VcfClient) (map[string]string, error) {
+	datastoresList, err := getClusterDatastores(ctx, clusterId, apiClient)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]string, len(datastoresList))
+	for _, datastore := range datastoresList {
+		if datastore != nil {
+			result[datastore.Name] = datastore.ID
+		}
+	}
+	return result, nil
+}
+
+func getClusterDatastores(ctx context.Context, clusterId string, apiClient *client.VcfClient) ([]*models.Datastore, error) {
+	getClusterDatastoresParams := clusters.NewGetClusterDatastoresParamsWithContext(ctx).
+		WithTimeout(constants.DefaultVcfApiCallTimeout)
+	getClusterDatastoresParams.ClusterID = clusterId
+	datastoresResult, err := apiClient.Clusters.GetClusterDatastores(getClusterDatastoresParams)
+	if err != nil {
+		return nil, err
+	}
+	return datastoresResult.Payload, nil
+}
+
+func tryConvertToDatastoreMountSpec(attributeName string, object map[string]interface{}) (*models.DatastoreMountSpec, error) {
+	datastoreSpec := &models.DatastoreSpec{}
+	switch attributeName {
+	case "nfs_datastores":
+		nfsDatastoreSpec, err := datastores.TryConvertToNfsDatastoreSpec(object)
+		if err != nil {
+			return nil, err
+		}
+		datastoreSpec.NfsDatastoreSpecs = []*models.NfsDatastoreSpec{nfsDatastoreSpec}
+	case "vvol_datastores":
+		vvolDatastoreSpec, err := datastores.TryConvertToVvolDatastoreSpec(object)
+		if err != nil {
+			return nil, err
+		}
+		datastoreSpec.VvolDatastoreSpecs = []*models.VvolDatastoreSpec{vvolDatastoreSpec}
+	default:
+		return nil, fmt.Errorf("mounting %s after the creation of the cluster is not supported", attributeName)
+	}
+	return &models.DatastoreMountSpec{DatastoreSpec: datastoreSpec}, nil
+}
+
+// validateMountedDatastoreUnchanged returns an error if the configuration of a mounted datastore changes.
+// Attributes not known in the state, e.g. after an import, are only stored.
+func validateMountedDatastoreUnchanged(datastoreName string, oldDatastore, newDatastore map[string]interface{}) error {
+	for attributeName, newAttributeValue := range newDatastore {
+		oldAttributeValue := oldDatastore[attributeName]
+		if validationUtils.IsEmpty(oldAttributeValue) {
+			continue
+		}
+		// the storage protocol type is case-insensitive, see its DiffSuppressFunc
+		if attributeName == "storage_protocol_type" &&
+			strings.EqualFold(oldAttributeValue.(string), newAttributeValue.(string)) {
+			continue
+		}
+		if !reflect.DeepEqual(oldAttributeValue, newAttributeValue) {
+			return fmt.Errorf("modifying %s of the mounted datastore %q is not supported, "+
+				"remove the datastore and add it with a different name", attributeName, datastoreName)
+		}
+	}
+	return nil
+}
+
+func sortedKeys(objectsByKey map[string]map[string]interface{}) []string {
+	result := make([]string, 0, len(objectsByKey))
+	for key := range objectsByKey {
+		result = append(result, key)
+	}
+	// Sort for reproducibility
+	sort.Strings(result)
+	return result
+}
@@ -0,0 +1,111 @@
+/*
+ *  Copyright 2023 VMware, Inc.
+ *    SPDX-License-Identifier: MPL-2.0
+ */
+
+package cluster
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func testNfsDatastore(datastoreName, path string) map[string]interface{} {
+	return map[string]interface{}{
+		"datastore_name": datastoreName,
+		"path":           path,
+		"read_only":      false,
+		"server_name":    "nfs.vrack.vsphere.local",
+		"user_tag":       "",
+	}
+}
+
+func testVvolDatastore(datastoreName, storageProtocolType string) map[string]interface{} {
+	return map[string]interface{}{
+		"datastore_name":        datastoreName,
+		"storage_container_id":  "4a7ed3d6-1c63-4ee3-9ee1-e5e7b5d2cdd1",
+		"storage_protocol_type": storageProtocolType,
+		"user_id":               "4f5cba4a-7f27-4d26-b5bc-0bd7b4f3a68e",
+		"vasa_provider_id":      "0d9d9fd5-b10c-45da-8e1d-4b6b2b53e6a7",
+	}
+}
+
+func TestCreateDatastoreMountChanges(t *testing.T) {
+	oldCluster := map[string]interface{}{
+		"primary_datastore_name": "nfs-ds-1",
+		"nfs_datastores": []interface{}{
+			testNfsDatastore("nfs-ds-1", "/nfs/ds1"),
+			testNfsDatastore("nfs-ds-2", "/nfs/ds2"),
+		},
+		"vvol_datastores": []interface{}{testVvolDatastore("vvol-ds-1", "ISCSI")},
+	}
+
+	var mountTests = []struct {
+		name            string
+		nfsDatastores   []interface{}
+		vvolDatastores  []interface{}
+		expectedMounted []string
+		expectedRemoved []string
+		expectedErr     string
+	}{
+		{"unchanged", oldCluster["nfs_datastores"].([]interface{}), oldCluster["vvol_datastores"].([]interface{}),
+			nil, nil, ""},
+		{"mount", []interface{}{
+			testNfsDatastore("nfs-ds-3", "/nfs/ds3"),
+			testNfsDatastore("nfs-ds-1", "/nfs/ds1"),
+			testNfsDatastore("nfs-ds-2", "/nfs/ds2"),
+		}, []interface{}{testVvolDatastore("vvol-ds-1", "ISCSI"), testVvolDatastore("vvol-ds-2", "NFS")},
+			[]string{"nfs-ds-3", "vvol-ds-2"}, nil, ""},
+		{"unmount", []interface{}{testNfsDatastore("nfs-ds-1", "/nfs/ds1")}, []interface{}{},
+			nil, []string{"nfs-ds-2", "vvol-ds-1"}, ""},
+		{"storage protocol type case", oldCluster["nfs_datastores"].([]interface{}),
+			[]interface{}{testVvolDatastore("vvol-ds-1", "iscsi")}, nil, nil, ""},
+		{"modify", []interface{}{
+			testNfsDatastore("nfs-ds-1", "/nfs/ds1"),
+			testNfsDatastore("nfs-ds-2", "/nfs/other"),
+		}, oldCluster["vvol_datastores"].([]interface{}),
+			nil, nil, "modifying path of the mounted datastore \"nfs-ds-2\" is not supported"},
+		{"remove primary datastore", []interface{}{testNfsDatastore("nfs-ds-2", "/nfs/ds2")},
+			oldCluster["vvol_datastores"].([]interface{}),
+			nil, nil, "removing the principal datastore \"nfs-ds-1\" of the cluster is not supported"},
+		{"incomplete datastore", append([]interface{}{testNfsDatastore("nfs-ds-3", "")},
+			oldCluster["nfs_datastores"].([]interface{})...), oldCluster["vvol_datastores"].([]interface{}),
+			nil, nil, "path is required"},
+	}
+	for _, mountTest := range mountTests {
+		newCluster := map[string]interface{}{
+			"nfs_datastores":  mountTest.nfsDatastores,
+			"vvol_datastores": mountTest.vvolDatastores,
+		}
+		mountSpecs, removedDatastoreNames, err := CreateDatastoreMountChanges(oldCluster, newCluster)
+		if len(mountTest.expectedErr) > 0 {
+			if err == nil || !strings.Contains(err.Error(), mountTest.expectedErr) {
+				t.Errorf("%s: expected error containing %q, got %v", mountTest.name, mountTest.expectedErr, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", mountTest.name, err)
+			continue
+		}
+
+		var mountedDatastoreNames []string
+		for _, mountSpec := range mountSpecs {
+			for _, nfsDatastoreSpec := range mountSpec.DatastoreSpec.NfsDatastoreSpecs {
+				mountedDatastoreNames = append(mountedDatastoreNames, *nfsDatastoreSpec.DatastoreName)
+			}
+			for _, vvolDatastoreSpec := range mountSpec.DatastoreSpec.VvolDatastoreSpecs {
+				mountedDatastoreNames = append(mountedDatastoreNames, *vvolDatastoreSpec.Name)
+			}
+		}
+		if !reflect.DeepEqual(mountedDatastoreNames, mountTest.expectedMounted) {
+			t.Errorf("%s: expected mounted datastores %v, got %v", mountTest.name, mountTest.expectedMounted,
+				mountedDatastoreNames)
+		}
+		if !reflect.DeepEqual(removedDatastoreNames, mountTest.expectedRemoved) {
+			t.Errorf("%s: expected removed datastores %v, got %v", mountTest.name, mountTest.expectedRemoved,
+				removedDatastoreNames)
+		}
+	}
+}
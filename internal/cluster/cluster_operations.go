@@ -21,8 +21,10 @@ import (
 	"github.com/vmware/vcf-sdk-go/client/hosts"
 	"github.com/vmware/vcf-sdk-go/models"
 	"sort"
+	"strings"
 )
 
+// CreateClusterUpdateSpec creates the ClusterUpdateSpec that renames a cluster or marks it for deletion.
 func CreateClusterUpdateSpec(data *schema.ResourceData, markForDeletion bool) (*models.ClusterUpdateSpec, error) {
 	result := new(models.ClusterUpdateSpec)
 	if markForDeletion {
@@ -33,45 +35,112 @@ func CreateClusterUpdateSpec(data *schema.ResourceData, markForDeletion bool) (*
 		result.Name = data.Get("name").(string)
 	}
 
-	// TODO support vSAN stretch/unstretch operations by adding a "witness" attribute to vcf_cluster and checking for change on it.
+	return result, nil
+}
+
+// CreateClusterUpdateSpecs creates the ClusterUpdateSpecs for the changes in the name and the hosts
+// of a cluster, in the order in which they have to be applied.
+func CreateClusterUpdateSpecs(data *schema.ResourceData) ([]*models.ClusterUpdateSpec, error) {
+	var result []*models.ClusterUpdateSpec
+	if data.HasChange("name") {
+		clusterUpdateSpec, err := CreateClusterUpdateSpec(data, false)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, clusterUpdateSpec)
+	}
+
 	if data.HasChange("host") {
-		oldHostsValue, newHostsValue := data.GetChange("host")
-		resultUpdated, err := SetExpansionOrContractionSpec(result,
-			oldHostsValue.([]interface{}), newHostsValue.([]interface{}))
+		// the new hosts are taken from the ResourceData, as their IDs may have been resolved by ResolveHostIdsInClusterData
+		oldHostsValue, _ := data.GetChange("host")
+		newHostsValue := data.Get("host")
+		expansionSpec, contractionSpec, err := CreateExpansionAndContractionSpecs(
+			oldHostsValue.(*schema.Set).List(), newHostsValue.(*schema.Set).List())
 		if err != nil {
 			return nil, err
 		}
-		return resultUpdated, nil
+		if expansionSpec != nil {
+			err = setStretchedClusterExpansionSpec(data, expansionSpec.ClusterExpansionSpec)
+			if err != nil {
+				return nil, err
+			}
+			err = ApplyNetworkProfile(expansionSpec.ClusterExpansionSpec.HostSpecs, networkProfileOfClusterData(data))
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, expansionSpec)
+		}
+		if contractionSpec != nil {
+			SetForcedContraction(contractionSpec.ClusterCompactionSpec, data.Get("force").(bool))
+			result = append(result, contractionSpec)
+		}
 	}
 
 	return result, nil
 }
 
-// SetExpansionOrContractionSpec sets ClusterExpansionSpec or ClusterContractionSpec to a provided
-// ClusterUpdateSpec depending on weather hosts are being added or removed.
-func SetExpansionOrContractionSpec(updateSpec *models.ClusterUpdateSpec,
-	oldHostsList, newHostsList []interface{}) (*models.ClusterUpdateSpec, error) {
+// SetForcedContraction makes the removal of hosts bypass the validations and the minimum size of the cluster,
+// so that dead or disconnected hosts can be removed.
+func SetForcedContraction(compactionSpec *models.ClusterCompactionSpec, force bool) {
+	compactionSpec.Force = force
+	compactionSpec.ForceByPassingSafeMinSize = force
+}
 
-	if len(newHostsList) == len(oldHostsList) {
-		return nil, fmt.Errorf("adding and removing hosts is not supported in a single configuration change. Apply each change separately")
+// CreateForcedContractionOfUnreachableHosts creates the ClusterUpdateSpec that removes the hosts of a
+// cluster, for which isReachable returns false, bypassing the validations of SDDC Manager. The last host
+// of a cluster can't be removed, so one host is kept if none is reachable. The spec is nil if all hosts
+// are reachable.
+func CreateForcedContractionOfUnreachableHosts(ctx context.Context, hostsById map[string]*models.Host,
+	isReachable func(ctx context.Context, address string) bool) *models.ClusterUpdateSpec {
+	hostIds := make([]string, 0, len(hostsById))
+	for hostId := range hostsById {
+		hostIds = append(hostIds, hostId)
 	}
+	// Sort the IDs, to keep and remove the same hosts in every run
+	sort.Strings(hostIds)
 
+	var unreachableHosts []*models.HostReference
+	for _, hostId := range hostIds {
+		if !isReachable(ctx, hostsById[hostId].Fqdn) {
+			unreachableHosts = append(unreachableHosts, &models.HostReference{ID: hostId})
+		}
+	}
+	if len(unreachableHosts) == len(hostIds) && len(unreachableHosts) > 0 {
+		unreachableHosts = unreachableHosts[1:]
+	}
+	if len(unreachableHosts) == 0 {
+		return nil
+	}
+	compactionSpec := &models.ClusterCompactionSpec{Hosts: unreachableHosts}
+	SetForcedContraction(compactionSpec, true)
+	return &models.ClusterUpdateSpec{ClusterCompactionSpec: compactionSpec}
+}
+
+// CreateExpansionAndContractionSpecs creates the ClusterUpdateSpecs that add the new hosts to a
+// cluster and remove the hosts that are no longer present, provided the old and new values of the
+// host list. The expansion has to be applied before the contraction, so that replacing a host
+// never lowers the capacity of the cluster. Either spec is nil if there are no hosts to add or remove.
+func CreateExpansionAndContractionSpecs(oldHostsList, newHostsList []interface{}) (
+	expansionSpec, contractionSpec *models.ClusterUpdateSpec, err error) {
 	addedHosts, removedHosts := resource_utils.CalculateAddedRemovedResources(newHostsList, oldHostsList)
-	if len(removedHosts) == 0 {
+
+	if len(addedHosts) > 0 {
 		var hostSpecs []*models.HostSpec
 		for _, addedHostRaw := range addedHosts {
 			hostSpec, err := TryConvertToHostSpec(addedHostRaw)
 			if err != nil {
-				return nil, err
+				return nil, nil, err
 			}
 			hostSpecs = append(hostSpecs, hostSpec)
 		}
-		clusterExpansionSpec := &models.ClusterExpansionSpec{
-			HostSpecs: hostSpecs,
+		expansionSpec = &models.ClusterUpdateSpec{
+			ClusterExpansionSpec: &models.ClusterExpansionSpec{
+				HostSpecs: hostSpecs,
+			},
 		}
-		updateSpec.ClusterExpansionSpec = clusterExpansionSpec
-		return updateSpec, nil
-	} else {
+	}
+
+	if len(removedHosts) > 0 {
 		var hostRefs []*models.HostReference
 		for _, removedHostRaw := range removedHosts {
 			hostRef := &models.HostReference{
@@ -79,12 +148,120 @@ func SetExpansionOrContractionSpec(updateSpec *models.ClusterUpdateSpec,
 			}
 			hostRefs = append(hostRefs, hostRef)
 		}
-		clusterContractionSpec := &models.ClusterCompactionSpec{
+		contractionSpec = &models.ClusterUpdateSpec{
+			ClusterCompactionSpec: &models.ClusterCompactionSpec{
+				Hosts: hostRefs,
+			},
+		}
+	}
+
+	return expansionSpec, contractionSpec, nil
+}
+
+// setStretchedClusterExpansionSpec adds the witness host configuration, required by the expansion of
+// a stretched cluster, to the provided ClusterExpansionSpec. Clusters that are not stretched by the
+// provider are left unchanged.
+func setStretchedClusterExpansionSpec(data *schema.ResourceData, expansionSpec *models.ClusterExpansionSpec) error {
+	oldStretchValue, _ := data.GetChange("stretch")
+	oldStretchList := oldStretchValue.([]interface{})
+	if len(oldStretchList) == 0 || oldStretchList[0] == nil {
+		return nil
+	}
+	stretch := oldStretchList[0].(map[string]interface{})
+	for _, hostSpec := range expansionSpec.HostSpecs {
+		if len(hostSpec.AzName) == 0 {
+			return fmt.Errorf("availability_zone_name is required for host %q when expanding a stretched cluster",
+				*hostSpec.ID)
+		}
+	}
+	expansionSpec.WitnessSpec = TryConvertToWitnessSpec(stretch)
+	expansionSpec.WitnessTrafficSharedWithVSANTraffic = stretch["witness_traffic_shared_with_vsan_traffic"].(bool)
+	expansionSpec.VSANNetworkSpecs = TryConvertToVsanNetworkSpecs(stretch)
+	return nil
+}
+
+// ValidateClusterStretchChange checks that the change on the "stretch" attribute of a cluster
+// can be applied. A cluster can be stretched or unstretched, but the stretch configuration of a
+// stretched cluster cannot be modified. The attributes of the cluster are read with the provided
+// functions, so that the change can be checked both in the plan and in the apply.
+func ValidateClusterStretchChange(stretchChanged bool, get func(string) interface{},
+	getChange func(string) (interface{}, interface{})) error {
+	if !stretchChanged {
+		return nil
+	}
+	oldStretchValue, newStretchValue := getChange("stretch")
+	oldStretchList := oldStretchValue.([]interface{})
+	newStretchList := newStretchValue.([]interface{})
+	if len(newStretchList) == 0 {
+		return nil
+	}
+	clusterName := get("name").(string)
+	if len(oldStretchList) > 0 {
+		return fmt.Errorf("modifying the stretch configuration of cluster %q is not supported, "+
+			"remove the stretch block to unstretch the cluster first", clusterName)
+	}
+	if validationUtils.IsEmpty(get("vsan_datastore")) {
+		return fmt.Errorf("cluster %q cannot be stretched, only clusters with vSAN primary storage can be stretched",
+			clusterName)
+	}
+	if newStretchList[0] == nil {
+		return fmt.Errorf("stretch configuration for cluster %q is empty", clusterName)
+	}
+	stretch := newStretchList[0].(map[string]interface{})
+	primaryAzHostsCount := get("host").(*schema.Set).Len()
+	secondaryAzHostsCount := len(stretch["secondary_az_host"].([]interface{}))
+	if primaryAzHostsCount != secondaryAzHostsCount {
+		return fmt.Errorf("cluster %q cannot be stretched, the number of hosts in the secondary availability zone (%d) "+
+			"must be equal to the number of hosts in the cluster (%d)", clusterName,
+			secondaryAzHostsCount, primaryAzHostsCount)
+	}
+	return nil
+}
+
+// CreateClusterStretchUpdateSpec creates the ClusterUpdateSpec that stretches a vSAN cluster
+// across two availability zones.
+func CreateClusterStretchUpdateSpec(data *schema.ResourceData) (*models.ClusterUpdateSpec, error) {
+	stretchList := data.Get("stretch").([]interface{})
+	if len(stretchList) == 0 || stretchList[0] == nil {
+		return nil, fmt.Errorf("cannot create ClusterStretchSpec, stretch configuration is not set")
+	}
+	clusterStretchSpec, err := TryConvertToClusterStretchSpec(stretchList[0].(map[string]interface{}))
+	if err != nil {
+		return nil, err
+	}
+	err = ApplyNetworkProfile(clusterStretchSpec.HostSpecs, networkProfileOfClusterData(data))
+	if err != nil {
+		return nil, err
+	}
+	return &models.ClusterUpdateSpec{
+		ClusterStretchSpec: clusterStretchSpec,
+	}, nil
+}
+
+// CreateClusterUnstretchUpdateSpecs creates the ClusterUpdateSpecs that unstretch a vSAN stretched
+// cluster, in the order they have to be applied. The hosts in the secondary availability zone are
+// removed from the cluster before the cluster is unstretched.
+func CreateClusterUnstretchUpdateSpecs(oldStretch map[string]interface{}, force bool) []*models.ClusterUpdateSpec {
+	var result []*models.ClusterUpdateSpec
+	var hostRefs []*models.HostReference
+	for _, hostRaw := range oldStretch["secondary_az_host"].([]interface{}) {
+		hostRefs = append(hostRefs, &models.HostReference{
+			ID: hostRaw.(map[string]interface{})["id"].(string),
+		})
+	}
+	if len(hostRefs) > 0 {
+		compactionSpec := &models.ClusterCompactionSpec{
 			Hosts: hostRefs,
 		}
-		updateSpec.ClusterCompactionSpec = clusterContractionSpec
-		return updateSpec, nil
+		SetForcedContraction(compactionSpec, force)
+		result = append(result, &models.ClusterUpdateSpec{
+			ClusterCompactionSpec: compactionSpec,
+		})
 	}
+	result = append(result, &models.ClusterUpdateSpec{
+		ClusterUnstretchSpec: map[string]interface{}{},
+	})
+	return result
 }
 
 func ValidateClusterUpdateOperation(ctx context.Context, clusterId string,
@@ -104,24 +281,127 @@ func ValidateClusterUpdateOperation(ctx context.Context, clusterId string,
 	return nil
 }
 
+// ResolveHostIdsInClusterData resolves the IDs of the hosts, referenced by FQDN, in the "host" and
+// "stretch" attributes of a cluster and stores them in the provided ResourceData, together with
+// the pinned SSH thumbprints of the hosts.
+func ResolveHostIdsInClusterData(ctx context.Context, data *schema.ResourceData, apiClient *client.VcfClient) error {
+	storageType := getPrincipalStorageTypeOfClusterData(data)
+
+	oldHostsValue, _ := data.GetChange("host")
+	hostsList := data.Get("host").(*schema.Set).List()
+	err := ResolveHostIds(ctx, hostsList, oldHostsValue.(*schema.Set).List(), data.Id(), storageType, apiClient)
+	if err != nil {
+		return err
+	}
+	if err = PinHostThumbprints(ctx, hostsList, oldHostsValue.(*schema.Set).List()); err != nil {
+		return err
+	}
+	_ = data.Set("host", hostsList)
+
+	stretchList := data.Get("stretch").([]interface{})
+	if len(stretchList) == 0 || stretchList[0] == nil {
+		return nil
+	}
+	var existingSecondaryAzHosts []interface{}
+	oldStretchValue, _ := data.GetChange("stretch")
+	if oldStretchList := oldStretchValue.([]interface{}); len(oldStretchList) > 0 && oldStretchList[0] != nil {
+		existingSecondaryAzHosts = oldStretchList[0].(map[string]interface{})["secondary_az_host"].([]interface{})
+	}
+	stretch := stretchList[0].(map[string]interface{})
+	err = ResolveHostIds(ctx, stretch["secondary_az_host"].([]interface{}), existingSecondaryAzHosts,
+		data.Id(), storageType, apiClient)
+	if err != nil {
+		return err
+	}
+	if err = PinHostThumbprints(ctx, stretch["secondary_az_host"].([]interface{}), existingSecondaryAzHosts); err != nil {
+		return err
+	}
+	_ = data.Set("stretch", stretchList)
+	return nil
+}
+
+// ApplyHostSelector selects the hosts of a cluster configured with "host_selector" and stores them
+// in the "host" attribute of the provided ResourceData. The hosts in the state are kept selected.
+func ApplyHostSelector(ctx context.Context, data *schema.ResourceData, apiClient *client.VcfClient) error {
+	selectorList := data.Get("host_selector").([]interface{})
+	if len(selectorList) == 0 || selectorList[0] == nil {
+		return nil
+	}
+	oldHostsValue, _ := data.GetChange("host")
+	hostsList, err := SelectHosts(ctx, selectorList[0].(map[string]interface{}), oldHostsValue.(*schema.Set).List(),
+		getPrincipalStorageTypeOfClusterData(data), apiClient)
+	if err != nil {
+		return err
+	}
+	_ = data.Set("host", hostsList)
+	return nil
+}
+
+func getPrincipalStorageTypeOfClusterData(data *schema.ResourceData) string {
+	return GetPrincipalStorageType(map[string]interface{}{
+		"vsan_datastore":                data.Get("vsan_datastore"),
+		"vsan_remote_datastore_cluster": data.Get("vsan_remote_datastore_cluster"),
+		"vmfs_datastore":                data.Get("vmfs_datastore"),
+		"nfs_datastores":                data.Get("nfs_datastores"),
+		"vvol_datastores":               data.Get("vvol_datastores"),
+	})
+}
+
 func TryConvertResourceDataToClusterSpec(data *schema.ResourceData) (*models.ClusterSpec, error) {
 	intermediaryMap := map[string]interface{}{}
 	intermediaryMap["name"] = data.Get("name")
-	intermediaryMap["clusterImageId"] = data.Get("clusterImageId")
+	intermediaryMap["cluster_image_id"] = data.Get("cluster_image_id")
 	intermediaryMap["evc_mode"] = data.Get("evc_mode")
 	intermediaryMap["high_availability_enabled"] = data.Get("high_availability_enabled")
 	intermediaryMap["geneve_vlan_id"] = data.Get("geneve_vlan_id")
-	intermediaryMap["host"] = data.Get("host")
+	intermediaryMap["host"] = data.Get("host").(*schema.Set).List()
 	intermediaryMap["vds"] = data.Get("vds")
 	intermediaryMap["vsan_datastore"] = data.Get("vsan_datastore")
 	intermediaryMap["vmfs_datastore"] = data.Get("vmfs_datastore")
 	intermediaryMap["vsan_remote_datastore_cluster"] = data.Get("vsan_remote_datastore_cluster")
 	intermediaryMap["nfs_datastores"] = data.Get("nfs_datastores")
 	intermediaryMap["vvol_datastores"] = data.Get("vvol_datastores")
+	intermediaryMap["vxrail_details"] = data.Get("vxrail_details")
+	intermediaryMap["network_profile"] = data.Get("network_profile")
 	return TryConvertToClusterSpec(intermediaryMap)
 }
 
-// TODO implement support for VxRailDetails.
+// ApplyNetworkProfile sets the host network configuration from the "network_profile" of a cluster
+// to the provided HostSpecs, that don't have a vmnic configuration of their own.
+func ApplyNetworkProfile(hostSpecs []*models.HostSpec, clusterObject map[string]interface{}) error {
+	networkProfileList, _ := clusterObject["network_profile"].([]interface{})
+	if len(networkProfileList) == 0 || networkProfileList[0] == nil {
+		return nil
+	}
+	networkProfile := networkProfileList[0].(map[string]interface{})
+
+	vdsNames := make(map[string]bool)
+	vdsList, _ := clusterObject["vds"].([]interface{})
+	for _, vdsRaw := range vdsList {
+		if vds, ok := vdsRaw.(map[string]interface{}); ok {
+			vdsNames[vds["name"].(string)] = true
+		}
+	}
+
+	for _, hostSpec := range hostSpecs {
+		if hostSpec.HostNetworkSpec != nil {
+			continue
+		}
+		hostNetworkSpec, err := network.TryConvertToHostNetworkSpec(networkProfile, vdsNames)
+		if err != nil {
+			return err
+		}
+		hostSpec.HostNetworkSpec = hostNetworkSpec
+	}
+	return nil
+}
+
+func networkProfileOfClusterData(data *schema.ResourceData) map[string]interface{} {
+	return map[string]interface{}{
+		"network_profile": data.Get("network_profile"),
+		"vds":             data.Get("vds"),
+	}
+}
 
 // TryConvertToClusterSpec is a convenience method that converts a map[string]interface{}
 // received from the Terraform SDK to an API struct, used in VCF API calls.
@@ -178,6 +458,10 @@ func TryConvertToClusterSpec(object map[string]interface{}) (*models.ClusterSpec
 	} else {
 		return nil, fmt.Errorf("cannot convert to ClusterSpec, hosts list is not set")
 	}
+	err := ApplyNetworkProfile(result.HostSpecs, object)
+	if err != nil {
+		return nil, err
+	}
 
 	if vdsRaw, ok := object["vds"]; ok {
 		vdsList := vdsRaw.([]interface{})
@@ -204,6 +488,18 @@ func TryConvertToClusterSpec(object map[string]interface{}) (*models.ClusterSpec
 		result.DatastoreSpec = datastoreSpec
 	}
 
+	if vxRailDetailsRaw, ok := object["vxrail_details"]; ok && !validationUtils.IsEmpty(vxRailDetailsRaw) {
+		vxRailDetailsList := vxRailDetailsRaw.([]interface{})
+		if len(vxRailDetailsList) > 1 {
+			return nil, fmt.Errorf("more than one vxrail_details config for cluster %q", name)
+		}
+		vxRailDetails, err := TryConvertToVxRailDetails(vxRailDetailsList[0].(map[string]interface{}))
+		if err != nil {
+			return nil, err
+		}
+		result.VxRailDetails = vxRailDetails
+	}
+
 	return result, nil
 }
 
@@ -284,7 +580,8 @@ func tryConvertToClusterDatastoreSpec(object map[string]interface{}, clusterName
 	return result, nil
 }
 
-func FlattenCluster(clusterObj *models.Cluster) *map[string]interface{} {
+// FlattenCluster flattens a cluster, with the details of its hosts taken from hostsById.
+func FlattenCluster(clusterObj *models.Cluster, hostsById map[string]*models.Host) *map[string]interface{} {
 	result := make(map[string]interface{})
 	if clusterObj == nil {
 		return &result
@@ -296,18 +593,298 @@ func FlattenCluster(clusterObj *models.Cluster) *map[string]interface{} {
 	result["primary_datastore_type"] = clusterObj.PrimaryDatastoreType
 	result["is_default"] = clusterObj.IsDefault
 	result["is_stretched"] = clusterObj.IsStretched
+	result["host"] = FlattenClusterHosts(clusterObj.Hosts, hostsById)
 
-	// TODO typically the VCF 4.5.1 returns only the IDs for the hosts inside models.Cluster
-	// consider getting the fqdn, ip and az name with an additional GET request
-	flattenedHosts := make([]map[string]interface{}, len(clusterObj.Hosts))
-	for j, host := range clusterObj.Hosts {
-		flattenedHosts[j] = *FlattenHostReference(host)
+	return &result
+}
+
+// GetExistingCluster returns an existing cluster in the shape of configuredCluster, so that the configuration
+// can be compared with the actual cluster. The hosts and the NFS and vVol datastores are taken from the
+// cluster, the attributes that the VCF API doesn't return or can't change are taken from configuredCluster.
+func GetExistingCluster(ctx context.Context, clusterObj *models.Cluster, configuredCluster map[string]interface{},
+	hostsById map[string]*models.Host, apiClient *client.VcfClient) (map[string]interface{}, error) {
+	datastoresList, err := getClusterDatastores(ctx, clusterObj.ID, apiClient)
+	if err != nil {
+		return nil, err
 	}
-	result["host"] = flattenedHosts
+	return MergeClusterWithConfiguration(clusterObj, datastoresList, configuredCluster, hostsById), nil
+}
 
-	return &result
+// MergeClusterWithConfiguration merges the flattened cluster, its hosts and its NFS and vVol datastores into
+// a copy of configuredCluster. The mounted datastores keep their configured settings, as only their names
+// are returned by the VCF API.
+func MergeClusterWithConfiguration(clusterObj *models.Cluster, datastoresList []*models.Datastore,
+	configuredCluster map[string]interface{}, hostsById map[string]*models.Host) map[string]interface{} {
+	result := make(map[string]interface{}, len(configuredCluster))
+	for attributeName, value := range configuredCluster {
+		result[attributeName] = value
+	}
+	for attributeName, value := range *FlattenCluster(clusterObj, hostsById) {
+		if attributeName == "host" {
+			continue
+		}
+		result[attributeName] = value
+	}
+
+	var hostsList []interface{}
+	for _, host := range FlattenClusterHosts(clusterObj.Hosts, hostsById) {
+		hostsList = append(hostsList, host)
+	}
+	result["host"] = hostsList
+
+	flattenedDatastores := FlattenClusterDatastores(datastoresList)
+	for _, attributeName := range supplementalDatastoreAttributes {
+		configuredDatastores, _ := configuredCluster[attributeName].([]interface{})
+		var datastoresList []interface{}
+		for _, datastore := range resource_utils.MergeWithStateByKey(configuredDatastores,
+			flattenedDatastores[attributeName], "datastore_name") {
+			datastoresList = append(datastoresList, datastore)
+		}
+		result[attributeName] = datastoresList
+	}
+	return result
 }
 
+// FlattenClusterHosts flattens the hosts of a cluster, sorted by ID. The VCF API returns only the IDs
+// inside the host references of a cluster, so the FQDN and the IP address are taken from hostsById.
+func FlattenClusterHosts(hostRefs []*models.HostReference, hostsById map[string]*models.Host) []map[string]interface{} {
+	sortedHostRefs := make([]*models.HostReference, 0, len(hostRefs))
+	for _, hostRef := range hostRefs {
+		if hostRef != nil {
+			sortedHostRefs = append(sortedHostRefs, hostRef)
+		}
+	}
+	// Sort for reproducibility
+	sort.SliceStable(sortedHostRefs, func(i, j int) bool {
+		return sortedHostRefs[i].ID < sortedHostRefs[j].ID
+	})
+
+	flattenedHosts := make([]map[string]interface{}, 0, len(sortedHostRefs))
+	for _, hostRef := range sortedHostRefs {
+		flattenedHost := *FlattenHostReference(hostRef)
+		if hostObj, ok := hostsById[hostRef.ID]; ok {
+			flattenedHost = resource_utils.MergeWithState(flattenedHost, *FlattenHost(hostObj))
+		}
+		flattenedHosts = append(flattenedHosts, flattenedHost)
+	}
+	return flattenedHosts
+}
+
+// FlattenClusterDatastores flattens the datastores of a cluster into the datastore attributes of
+// the cluster schema. Only the datastore names are returned by the VCF API.
+func FlattenClusterDatastores(datastoresList []*models.Datastore) map[string][]map[string]interface{} {
+	sortedDatastores := make([]*models.Datastore, 0, len(datastoresList))
+	for _, datastore := range datastoresList {
+		if datastore != nil {
+			sortedDatastores = append(sortedDatastores, datastore)
+		}
+	}
+	// Sort for reproducibility
+	sort.SliceStable(sortedDatastores, func(i, j int) bool {
+		return sortedDatastores[i].Name < sortedDatastores[j].Name
+	})
+
+	result := map[string][]map[string]interface{}{
+		"vsan_datastore":  {},
+		"vmfs_datastore":  {},
+		"nfs_datastores":  {},
+		"vvol_datastores": {},
+	}
+	var vmfsDatastoreNames []string
+	for _, datastore := range sortedDatastores {
+		datastoreType := strings.ToUpper(datastore.DatastoreType)
+		switch {
+		case strings.HasPrefix(datastoreType, "VSAN_REMOTE"):
+			// the remote datastore UUIDs are not returned, "vsan_remote_datastore_cluster" is kept from the configuration
+			continue
+		case strings.HasPrefix(datastoreType, "VSAN"):
+			result["vsan_datastore"] = append(result["vsan_datastore"], map[string]interface{}{
+				"datastore_name": datastore.Name,
+			})
+		case strings.HasPrefix(datastoreType, "VMFS"):
+			vmfsDatastoreNames = append(vmfsDatastoreNames, datastore.Name)
+		case strings.HasPrefix(datastoreType, "NFS"):
+			result["nfs_datastores"] = append(result["nfs_datastores"], map[string]interface{}{
+				"datastore_name": datastore.Name,
+			})
+		case strings.HasPrefix(datastoreType, "VVOL"):
+			result["vvol_datastores"] = append(result["vvol_datastores"], map[string]interface{}{
+				"datastore_name": datastore.Name,
+			})
+		}
+	}
+	if len(vmfsDatastoreNames) > 0 {
+		result["vmfs_datastore"] = append(result["vmfs_datastore"], map[string]interface{}{
+			"datastore_names": vmfsDatastoreNames,
+		})
+	}
+	return result
+}
+
+// GetAllHosts fetches all hosts known to SDDC Manager with a single request and returns them indexed by ID.
+func GetAllHosts(ctx context.Context, apiClient *client.VcfClient) (map[string]*models.Host, error) {
+	getHostsParams := hosts.NewGetHostsParamsWithContext(ctx).
+		WithTimeout(constants.DefaultVcfApiCallTimeout)
+	return getHostsById(getHostsParams, apiClient)
+}
+
+// GetHostsInCluster fetches all hosts of a cluster with a single request and returns them indexed by ID.
+func GetHostsInCluster(ctx context.Context, clusterId string, apiClient *client.VcfClient) (map[string]*models.Host, error) {
+	getHostsParams := hosts.NewGetHostsParamsWithContext(ctx).
+		WithTimeout(constants.DefaultVcfApiCallTimeout)
+	getHostsParams.ClusterID = &clusterId
+	return getHostsById(getHostsParams, apiClient)
+}
+
+// GetHostsInDomain fetches all hosts of a domain with a single request and returns them indexed by ID.
+func GetHostsInDomain(ctx context.Context, domainId string, apiClient *client.VcfClient) (map[string]*models.Host, error) {
+	getHostsParams := hosts.NewGetHostsParamsWithContext(ctx).
+		WithTimeout(constants.DefaultVcfApiCallTimeout)
+	getHostsParams.DomainID = &domainId
+	return getHostsById(getHostsParams, apiClient)
+}
+
+func getHostsById(getHostsParams *hosts.GetHostsParams, apiClient *client.VcfClient) (map[string]*models.Host, error) {
+	hostsResult, err := apiClient.Hosts.GetHosts(getHostsParams)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]*models.Host, len(hostsResult.Payload.Elements))
+	for _, hostObj := range hostsResult.Payload.Elements {
+		if hostObj != nil {
+			result[hostObj.ID] = hostObj
+		}
+	}
+	return result, nil
+}
+
+// SetClusterData sets the attributes of a cluster that are returned by the VCF API, i.e. the name,
+// the hosts with their details, the datastores and the computed attributes, to the provided ResourceData.
+// The hosts and the datastores are merged with the ones in the state, so that the attributes that
+// are only used during creation, e.g. license keys and passwords, are preserved. The passwords of
+// the hosts are stored as salted hashes.
+// The hosts that are not in the state yet, e.g. after an import, are identified by their FQDN.
+// The EVC mode, the vSphere HA settings and the Geneve VLAN ID are not returned by the VCF API and
+// are kept from the configuration, vcf_cluster reads the EVC mode and vSphere HA from vCenter Server.
+func SetClusterData(ctx context.Context, data *schema.ResourceData, clusterObj *models.Cluster,
+	apiClient *client.VcfClient) error {
+	_ = data.Set("name", clusterObj.Name)
+	_ = data.Set("primary_datastore_name", clusterObj.PrimaryDatastoreName)
+	_ = data.Set("primary_datastore_type", clusterObj.PrimaryDatastoreType)
+	_ = data.Set("is_default", clusterObj.IsDefault)
+	_ = data.Set("is_stretched", clusterObj.IsStretched)
+
+	hostsById, err := GetHostsInCluster(ctx, clusterObj.ID, apiClient)
+	if err != nil {
+		return err
+	}
+	// the hosts in the secondary availability zone of a stretched cluster are managed in the "stretch" block
+	secondaryAzHostIds := getSecondaryAzHostIds(data)
+	var hostRefs []*models.HostReference
+	for _, hostRef := range clusterObj.Hosts {
+		if hostRef != nil && !secondaryAzHostIds[hostRef.ID] {
+			hostRefs = append(hostRefs, hostRef)
+		}
+	}
+	stateHostsList := listFromState(data.Get("host"))
+	hostsList := resource_utils.MergeWithStateByKey(stateHostsList, FlattenClusterHosts(hostRefs, hostsById), "id")
+	setFqdnOfNewHosts(hostsList, stateHostsList)
+	for _, host := range hostsList {
+		hashHostPassword(host)
+	}
+	_ = data.Set("host", hostsList)
+	hashSecondaryAzHostPasswords(data)
+
+	datastoresList, err := getClusterDatastores(ctx, clusterObj.ID, apiClient)
+	if err != nil {
+		return err
+	}
+	for attributeName, flattenedDatastores := range FlattenClusterDatastores(datastoresList) {
+		stateDatastores := listFromState(data.Get(attributeName))
+		if attributeName == "vmfs_datastore" {
+			// a single block holds the names of all VMFS datastores
+			if len(stateDatastores) > 0 && len(flattenedDatastores) > 0 {
+				stateDatastore, _ := stateDatastores[0].(map[string]interface{})
+				flattenedDatastores[0] = resource_utils.MergeWithState(stateDatastore, flattenedDatastores[0])
+			}
+			_ = data.Set(attributeName, flattenedDatastores)
+			continue
+		}
+		_ = data.Set(attributeName, resource_utils.MergeWithStateByKey(stateDatastores,
+			flattenedDatastores, "datastore_name"))
+	}
+
+	return nil
+}
+
+// setFqdnOfNewHosts sets the FQDN of the hosts that are not in the state to their host name. The hosts
+// in the state keep the attribute they are configured with, which identifies them in the "host" set.
+func setFqdnOfNewHosts(hostsList []map[string]interface{}, stateHostsList []interface{}) {
+	stateHostIds := make(map[string]bool, len(stateHostsList))
+	for _, stateHostRaw := range stateHostsList {
+		if stateHost, ok := stateHostRaw.(map[string]interface{}); ok {
+			stateHostId, _ := stateHost["id"].(string)
+			stateHostIds[stateHostId] = true
+		}
+	}
+	for _, host := range hostsList {
+		hostId, _ := host["id"].(string)
+		if hostName, _ := host["host_name"].(string); !stateHostIds[hostId] && len(hostName) > 0 {
+			host["fqdn"] = hostName
+		}
+	}
+}
+
+func getSecondaryAzHostIds(data *schema.ResourceData) map[string]bool {
+	result := make(map[string]bool)
+	stretchList, _ := data.Get("stretch").([]interface{})
+	if len(stretchList) == 0 || stretchList[0] == nil {
+		return result
+	}
+	secondaryAzHosts, _ := stretchList[0].(map[string]interface{})["secondary_az_host"].([]interface{})
+	for _, hostRaw := range secondaryAzHosts {
+		if host, ok := hostRaw.(map[string]interface{}); ok {
+			hostId, _ := host["id"].(string)
+			result[hostId] = true
+		}
+	}
+	return result
+}
+
+// hashSecondaryAzHostPasswords replaces the passwords of the hosts in the secondary availability zone,
+// that are set to the ResourceData while the cluster is stretched, with their hashes.
+func hashSecondaryAzHostPasswords(data *schema.ResourceData) {
+	stretchList, _ := data.Get("stretch").([]interface{})
+	if len(stretchList) == 0 || stretchList[0] == nil {
+		return
+	}
+	stretch := stretchList[0].(map[string]interface{})
+	secondaryAzHosts, _ := stretch["secondary_az_host"].([]interface{})
+	stretch["secondary_az_host"] = HashHostPasswords(secondaryAzHosts)
+	_ = data.Set("stretch", stretchList)
+}
+
+// listFromState returns the elements of a list or a set attribute.
+func listFromState(value interface{}) []interface{} {
+	switch typedValue := value.(type) {
+	case *schema.Set:
+		return typedValue.List()
+	case []interface{}:
+		return typedValue
+	}
+	return nil
+}
+
+// ImportCluster sets the attributes of an existing cluster, that the VCF API returns, to the ResourceData.
+// The hosts are identified by their FQDN and vcf_cluster reads the EVC mode and vSphere HA from vCenter
+// Server. An imported cluster still doesn't plan with an empty diff against the configuration it was
+// created with, because these attributes are not returned by any API:
+//   - the license keys, the credentials, the serial numbers, the SSH thumbprints and the vmnics of the hosts,
+//   - the Geneve VLAN ID, the network profile and the datastore settings other than the names.
+//
+// Hosts configured by ID are planned as replaced set elements, as the set is keyed by the FQDN in the state.
+// The next apply only stores the configured values of these attributes in the state. The creation-only
+// attributes "cluster_image_id" and "vxrail_details" are not known either and fail the plan if they are set.
 func ImportCluster(ctx context.Context, data *schema.ResourceData, apiClient *client.VcfClient, clusterId string) ([]*schema.ResourceData, error) {
 	getClusterParams := clusters.NewGetClusterParamsWithContext(ctx).
 		WithTimeout(constants.DefaultVcfApiCallTimeout)
@@ -319,11 +896,6 @@ func ImportCluster(ctx context.Context, data *schema.ResourceData, apiClient *cl
 	clusterObj := clusterResult.Payload
 
 	data.SetId(clusterObj.ID)
-	_ = data.Set("name", clusterObj.Name)
-	_ = data.Set("primary_datastore_name", clusterObj.PrimaryDatastoreName)
-	_ = data.Set("primary_datastore_type", clusterObj.PrimaryDatastoreType)
-	_ = data.Set("is_default", clusterObj.IsDefault)
-	_ = data.Set("is_stretched", clusterObj.IsStretched)
 	flattenedVdsSpecs := *new([]map[string]interface{})
 	vdsSpecs := clusterObj.VdsSpecs
 	// Since backend API returns objects in random order sort VDSSpec list to ensure
@@ -336,27 +908,10 @@ func ImportCluster(ctx context.Context, data *schema.ResourceData, apiClient *cl
 	}
 	_ = data.Set("vds", flattenedVdsSpecs)
 
-	// The HostRef is supposed to have all the relevant information, but the backend returns
-	// everything as nil except the host ID which forces us to make a separate request
-	// to get some useful info about the hosts in the cluster.
-	flattenedHostSpecs := *new([]map[string]interface{})
-	hostRefs := clusterObj.Hosts
-	// Sort for reproducibility
-	sort.SliceStable(hostRefs, func(i, j int) bool {
-		return hostRefs[i].ID < hostRefs[j].ID
-	})
-	for _, hostRef := range hostRefs {
-		getHostParams := hosts.NewGetHostParamsWithContext(ctx).
-			WithTimeout(constants.DefaultVcfApiCallTimeout)
-		getHostParams.ID = hostRef.ID
-		getHostResult, err := apiClient.Hosts.GetHost(getHostParams)
-		if err != nil {
-			return nil, err
-		}
-		hostObj := getHostResult.Payload
-		flattenedHostSpecs = append(flattenedHostSpecs, *FlattenHost(hostObj))
+	err = SetClusterData(ctx, data, clusterObj, apiClient)
+	if err != nil {
+		return nil, err
 	}
-	_ = data.Set("host", flattenedHostSpecs)
 
 	//get all domains and find our cluster to set the "domain_id" attribute, because
 	// cluster API doesn't provide parent domain ID.
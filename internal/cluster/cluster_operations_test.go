@@ -0,0 +1,256 @@
+/*
+ *  Copyright 2023 VMware, Inc.
+ *    SPDX-License-Identifier: MPL-2.0
+ */
+
+package cluster
+
+import (
+	"context"
+	"github.com/vmware/vcf-sdk-go/models"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCreateExpansionAndContractionSpecs(t *testing.T) {
+	hostsList := func(hostIds ...string) []interface{} {
+		var result []interface{}
+		for _, hostId := range hostIds {
+			result = append(result, map[string]interface{}{"id": hostId, "license_key": "XX0XX-XX0XX"})
+		}
+		return result
+	}
+
+	var specTests = []struct {
+		name            string
+		oldHosts        []interface{}
+		newHosts        []interface{}
+		expectedAdded   []string
+		expectedRemoved []string
+	}{
+		{"unchanged", hostsList("host-1", "host-2"), hostsList("host-2", "host-1"), nil, nil},
+		{"add", hostsList("host-1", "host-2"), hostsList("host-1", "host-2", "host-3"), []string{"host-3"}, nil},
+		{"remove", hostsList("host-1", "host-2", "host-3"), hostsList("host-1", "host-3"), nil, []string{"host-2"}},
+		{"replace", hostsList("host-1", "host-2", "host-3"), hostsList("host-1", "host-3", "host-4"),
+			[]string{"host-4"}, []string{"host-2"}},
+		{"replace all", hostsList("host-1", "host-2"), hostsList("host-3", "host-4"),
+			[]string{"host-3", "host-4"}, []string{"host-1", "host-2"}},
+	}
+	for _, specTest := range specTests {
+		expansionSpec, contractionSpec, err := CreateExpansionAndContractionSpecs(specTest.oldHosts, specTest.newHosts)
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", specTest.name, err)
+			continue
+		}
+
+		var addedHostIds []string
+		if expansionSpec != nil {
+			for _, hostSpec := range expansionSpec.ClusterExpansionSpec.HostSpecs {
+				addedHostIds = append(addedHostIds, *hostSpec.ID)
+				if hostSpec.LicenseKey != "XX0XX-XX0XX" {
+					t.Errorf("%s: expected the license key of host %q in the expansion spec", specTest.name, *hostSpec.ID)
+				}
+			}
+		}
+		if !reflect.DeepEqual(addedHostIds, specTest.expectedAdded) {
+			t.Errorf("%s: expected added hosts %v, got %v", specTest.name, specTest.expectedAdded, addedHostIds)
+		}
+
+		var removedHostIds []string
+		if contractionSpec != nil {
+			for _, hostRef := range contractionSpec.ClusterCompactionSpec.Hosts {
+				removedHostIds = append(removedHostIds, hostRef.ID)
+			}
+			if contractionSpec.ClusterCompactionSpec.Force {
+				t.Errorf("%s: expected the contraction not to be forced by default", specTest.name)
+			}
+		}
+		if !reflect.DeepEqual(removedHostIds, specTest.expectedRemoved) {
+			t.Errorf("%s: expected removed hosts %v, got %v", specTest.name, specTest.expectedRemoved, removedHostIds)
+		}
+	}
+
+	_, _, err := CreateExpansionAndContractionSpecs(hostsList("host-1"),
+		[]interface{}{map[string]interface{}{"id": "", "fqdn": "esxi-2.vrack.vsphere.local"}})
+	if err == nil || !strings.Contains(err.Error(), "is not resolved") {
+		t.Errorf("expected an error for a host without resolved ID, got %v", err)
+	}
+}
+
+func TestSetForcedContraction(t *testing.T) {
+	var forceTests = []struct {
+		name  string
+		spec  *models.ClusterCompactionSpec
+		force bool
+	}{
+		{"force", &models.ClusterCompactionSpec{}, true},
+		{"no force", &models.ClusterCompactionSpec{}, false},
+		{"reset force", &models.ClusterCompactionSpec{Force: true, ForceByPassingSafeMinSize: true}, false},
+	}
+	for _, forceTest := range forceTests {
+		SetForcedContraction(forceTest.spec, forceTest.force)
+		if forceTest.spec.Force != forceTest.force || forceTest.spec.ForceByPassingSafeMinSize != forceTest.force {
+			t.Errorf("%s: expected Force and ForceByPassingSafeMinSize to be %t, got %+v", forceTest.name,
+				forceTest.force, forceTest.spec)
+		}
+	}
+}
+
+func TestApplyNetworkProfile(t *testing.T) {
+	hostId1, hostId2 := "host-1", "host-2"
+	ownHostNetworkSpec := &models.HostNetworkSpec{VMNics: []*models.VMNic{{ID: "vmnic2", VdsName: "sfo-w01-cl01-vds01"}}}
+	hostSpecs := []*models.HostSpec{
+		{ID: &hostId1},
+		{ID: &hostId2, HostNetworkSpec: ownHostNetworkSpec},
+	}
+	clusterObject := map[string]interface{}{
+		"vds": []interface{}{map[string]interface{}{"name": "sfo-w01-cl01-vds01"}},
+		"network_profile": []interface{}{map[string]interface{}{"vmnic": []interface{}{
+			map[string]interface{}{"id": "vmnic0", "vds_name": "sfo-w01-cl01-vds01"},
+			map[string]interface{}{"id": "vmnic1", "move_to_nvds": true},
+		}}},
+	}
+	err := ApplyNetworkProfile(hostSpecs, clusterObject)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedVmNics := []*models.VMNic{
+		{ID: "vmnic0", VdsName: "sfo-w01-cl01-vds01"},
+		{ID: "vmnic1", MoveToNvds: true},
+	}
+	if hostSpecs[0].HostNetworkSpec == nil || !reflect.DeepEqual(hostSpecs[0].HostNetworkSpec.VMNics, expectedVmNics) {
+		t.Errorf("expected the network profile to be applied to the host without vmnics, got %+v", hostSpecs[0].HostNetworkSpec)
+	}
+	if hostSpecs[1].HostNetworkSpec != ownHostNetworkSpec {
+		t.Errorf("expected the vmnics of the host to take precedence over the network profile")
+	}
+
+	clusterObject["vds"] = []interface{}{map[string]interface{}{"name": "sfo-w01-cl01-vds02"}}
+	err = ApplyNetworkProfile([]*models.HostSpec{{ID: &hostId1}}, clusterObject)
+	if err == nil || !strings.Contains(err.Error(), "which is not part of the cluster") {
+		t.Errorf("expected an error for a vmnic associated with a VDS outside the cluster, got %v", err)
+	}
+
+	hostSpecs = []*models.HostSpec{{ID: &hostId1}}
+	err = ApplyNetworkProfile(hostSpecs, map[string]interface{}{"network_profile": []interface{}{}})
+	if err != nil || hostSpecs[0].HostNetworkSpec != nil {
+		t.Errorf("expected the hosts to be unchanged without network profile, got %v", err)
+	}
+}
+
+func TestCreateForcedContractionOfUnreachableHosts(t *testing.T) {
+	hostsById := map[string]*models.Host{
+		"host-1": {ID: "host-1", Fqdn: "esxi-1.vrack.vsphere.local"},
+		"host-2": {ID: "host-2", Fqdn: "esxi-2.vrack.vsphere.local"},
+		"host-3": {ID: "host-3", Fqdn: "esxi-3.vrack.vsphere.local"},
+	}
+	var contractionTests = []struct {
+		name               string
+		reachableHosts     map[string]bool
+		expectedRemovedIds []string
+	}{
+		{"all reachable", map[string]bool{"esxi-1.vrack.vsphere.local": true, "esxi-2.vrack.vsphere.local": true,
+			"esxi-3.vrack.vsphere.local": true}, nil},
+		{"some unreachable", map[string]bool{"esxi-2.vrack.vsphere.local": true}, []string{"host-1", "host-3"}},
+		{"none reachable", map[string]bool{}, []string{"host-2", "host-3"}},
+	}
+	for _, contractionTest := range contractionTests {
+		updateSpec := CreateForcedContractionOfUnreachableHosts(context.Background(), hostsById,
+			func(_ context.Context, address string) bool { return contractionTest.reachableHosts[address] })
+		var removedHostIds []string
+		if updateSpec != nil {
+			compactionSpec := updateSpec.ClusterCompactionSpec
+			if !compactionSpec.Force || !compactionSpec.ForceByPassingSafeMinSize {
+				t.Errorf("%s: expected the removal of the hosts to be forced", contractionTest.name)
+			}
+			for _, hostRef := range compactionSpec.Hosts {
+				removedHostIds = append(removedHostIds, hostRef.ID)
+			}
+		}
+		if !reflect.DeepEqual(removedHostIds, contractionTest.expectedRemovedIds) {
+			t.Errorf("%s: expected removed hosts %v, got %v", contractionTest.name, contractionTest.expectedRemovedIds,
+				removedHostIds)
+		}
+	}
+}
+
+func TestMergeClusterWithConfiguration(t *testing.T) {
+	nfsDatastore := func(datastoreName string) map[string]interface{} {
+		return map[string]interface{}{
+			"datastore_name": datastoreName,
+			"path":           "/" + datastoreName,
+			"read_only":      false,
+			"server_name":    "nfs.vrack.vsphere.local",
+		}
+	}
+	configuredCluster := map[string]interface{}{
+		"name":     "sfo-m01-cl01",
+		"evc_mode": "INTEL_SKYLAKE",
+		"host": []interface{}{
+			map[string]interface{}{"fqdn": "esxi-1.vrack.vsphere.local"},
+			map[string]interface{}{"fqdn": "esxi-3.vrack.vsphere.local"},
+		},
+		"nfs_datastores": []interface{}{nfsDatastore("nfs-1"), nfsDatastore("nfs-3")},
+	}
+	clusterObj := &models.Cluster{
+		ID:    "cluster-1",
+		Name:  "sfo-m01-cl01",
+		Hosts: []*models.HostReference{{ID: "host-2"}, {ID: "host-1"}},
+	}
+	hostsById := map[string]*models.Host{
+		"host-1": {ID: "host-1", Fqdn: "esxi-1.vrack.vsphere.local"},
+		"host-2": {ID: "host-2", Fqdn: "esxi-2.vrack.vsphere.local"},
+	}
+	datastoresList := []*models.Datastore{
+		{Name: "nfs-2", DatastoreType: "NFS"},
+		{Name: "nfs-1", DatastoreType: "NFS"},
+		{Name: "sfo-m01-cl01-ds-vsan01", DatastoreType: "VSAN"},
+	}
+
+	result := MergeClusterWithConfiguration(clusterObj, datastoresList, configuredCluster, hostsById)
+
+	if result["id"] != "cluster-1" || result["evc_mode"] != "INTEL_SKYLAKE" {
+		t.Errorf("expected the ID of the cluster and the configured EVC mode, got %v", result)
+	}
+	var hostNames []string
+	for _, host := range result["host"].([]interface{}) {
+		hostNames = append(hostNames, host.(map[string]interface{})["host_name"].(string))
+	}
+	if !reflect.DeepEqual(hostNames, []string{"esxi-1.vrack.vsphere.local", "esxi-2.vrack.vsphere.local"}) {
+		t.Errorf("expected the hosts of the cluster, got %v", hostNames)
+	}
+	expectedDatastores := []interface{}{nfsDatastore("nfs-1"), map[string]interface{}{"datastore_name": "nfs-2"}}
+	if !reflect.DeepEqual(result["nfs_datastores"], expectedDatastores) {
+		t.Errorf("expected the mounted datastores with their configured settings, got %v", result["nfs_datastores"])
+	}
+	if len(configuredCluster["host"].([]interface{})) != 2 || configuredCluster["id"] != nil {
+		t.Errorf("expected the configured cluster to be unchanged, got %v", configuredCluster)
+	}
+
+	_, removedDatastoreNames, err := CreateDatastoreMountChanges(result, configuredCluster)
+	if err != nil || !reflect.DeepEqual(removedDatastoreNames, []string{"nfs-2"}) {
+		t.Errorf("expected the datastore that is not configured to be removed, got %v, %v", removedDatastoreNames, err)
+	}
+}
+
+func TestSetFqdnOfNewHosts(t *testing.T) {
+	hostsList := []map[string]interface{}{
+		{"id": "host-1", "host_name": "esxi-1.vrack.vsphere.local"},
+		{"id": "host-2", "host_name": "esxi-2.vrack.vsphere.local"},
+		{"id": "host-3", "host_name": ""},
+	}
+	stateHostsList := []interface{}{map[string]interface{}{"id": "host-1"}}
+
+	setFqdnOfNewHosts(hostsList, stateHostsList)
+
+	if _, ok := hostsList[0]["fqdn"]; ok {
+		t.Errorf("expected the host in the state to keep its attributes, got %v", hostsList[0])
+	}
+	if hostsList[1]["fqdn"] != "esxi-2.vrack.vsphere.local" {
+		t.Errorf("expected the FQDN of the new host to be set, got %v", hostsList[1])
+	}
+	if _, ok := hostsList[2]["fqdn"]; ok {
+		t.Errorf("expected no FQDN for the host without a host name, got %v", hostsList[2])
+	}
+}
@@ -0,0 +1,196 @@
+/*
+ *  Copyright 2023 VMware, Inc.
+ *    SPDX-License-Identifier: MPL-2.0
+ */
+
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/vmware/terraform-provider-vcf/internal/constants"
+	"github.com/vmware/terraform-provider-vcf/internal/network"
+	validationutils "github.com/vmware/terraform-provider-vcf/internal/validation"
+	"github.com/vmware/vcf-sdk-go/client"
+	"github.com/vmware/vcf-sdk-go/client/hosts"
+	"github.com/vmware/vcf-sdk-go/models"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// HostSelectorSchema this helper function extracts the schema for selecting hosts from the free pool.
+func HostSelectorSchema() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"count": {
+				Type:         schema.TypeInt,
+				Required:     true,
+				Description:  "Number of hosts in the cluster",
+				ValidateFunc: validation.IntAtLeast(2),
+			},
+			"network_pool_id": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  "Select only hosts associated with the network pool with this ID",
+				ValidateFunc: validation.NoZeroValues,
+			},
+			"storage_type": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Description: "Select only hosts commissioned with this storage type. One among: VSAN, VSAN_REMOTE, " +
+					"NFS, VMFS_FC, VVOL. Defaults to the type of the principal storage of the cluster",
+				ValidateFunc: validation.StringInSlice([]string{"VSAN", "VSAN_REMOTE", "NFS", "VMFS_FC", "VVOL"}, false),
+			},
+			"fqdn_pattern": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  "Select only hosts whose FQDN matches this regular expression",
+				ValidateFunc: validation.StringIsValidRegExp,
+			},
+			"availability_zone_name": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  "Availability Zone Name assigned to the selected hosts, the hosts are not filtered by it",
+				ValidateFunc: validation.NoZeroValues,
+			},
+			"license_key": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Sensitive:    true,
+				Description:  "License key for the selected hosts",
+				ValidateFunc: validation.NoZeroValues,
+			},
+			"vmnic": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Description: "vmnic configuration applied to each of the selected hosts",
+				Elem:        network.VMNicSchema(),
+			},
+		},
+	}
+}
+
+// SelectHosts returns the hosts of a cluster after applying the host selector. The hosts that are
+// already selected are kept, so that the choice is stable across plans. If more hosts are needed,
+// eligible hosts are picked from the free pool, sorted by FQDN. If fewer hosts are needed, the
+// hosts with the greatest FQDNs are released.
+func SelectHosts(ctx context.Context, selector map[string]interface{}, selectedHostsList []interface{},
+	defaultStorageType string, apiClient *client.VcfClient) ([]interface{}, error) {
+	return selectHosts(selector, selectedHostsList, defaultStorageType, func() ([]*models.Host, error) {
+		return getFreePoolHosts(ctx, selector, apiClient)
+	})
+}
+
+// selectHosts is SelectHosts with the hosts in the free pool provided by getFreePoolHosts, that is
+// called only if more hosts are needed.
+func selectHosts(selector map[string]interface{}, selectedHostsList []interface{}, defaultStorageType string,
+	getFreePoolHosts func() ([]*models.Host, error)) ([]interface{}, error) {
+	count := selector["count"].(int)
+	result := make([]interface{}, len(selectedHostsList))
+	copy(result, selectedHostsList)
+	// Sort for reproducibility
+	sort.SliceStable(result, func(i, j int) bool {
+		return hostFqdn(result[i]) < hostFqdn(result[j])
+	})
+
+	if len(result) >= count {
+		return result[:count], nil
+	}
+
+	freePoolHosts, err := getFreePoolHosts()
+	if err != nil {
+		return nil, err
+	}
+	eligibleHosts, err := filterEligibleHosts(freePoolHosts, selector, defaultStorageType)
+	if err != nil {
+		return nil, err
+	}
+	if len(eligibleHosts) < count-len(result) {
+		return nil, fmt.Errorf("not enough hosts in the free pool match the host selector, %d more needed, "+
+			"%d found", count-len(result), len(eligibleHosts))
+	}
+	for _, hostObj := range eligibleHosts[:count-len(result)] {
+		selectedHost := map[string]interface{}{
+			"id":        hostObj.ID,
+			"host_name": hostObj.Fqdn,
+		}
+		if len(hostObj.IPAddresses) > 0 && hostObj.IPAddresses[0] != nil {
+			selectedHost["ip_address"] = hostObj.IPAddresses[0].IPAddress
+		}
+		for _, attributeName := range []string{"availability_zone_name", "license_key", "vmnic"} {
+			if value, ok := selector[attributeName]; ok && !validationutils.IsEmpty(value) {
+				selectedHost[attributeName] = value
+			}
+		}
+		result = append(result, selectedHost)
+	}
+	return result, nil
+}
+
+func getFreePoolHosts(ctx context.Context, selector map[string]interface{},
+	apiClient *client.VcfClient) ([]*models.Host, error) {
+	getHostsParams := hosts.NewGetHostsParamsWithContext(ctx).
+		WithTimeout(constants.DefaultVcfApiCallTimeout)
+	status := HostStatusUnassignedUseable
+	getHostsParams.Status = &status
+	if networkPoolId, ok := selector["network_pool_id"]; ok && !validationutils.IsEmpty(networkPoolId) {
+		networkPoolIdStr := networkPoolId.(string)
+		getHostsParams.NetworkpoolID = &networkPoolIdStr
+	}
+	hostsResult, err := apiClient.Hosts.GetHosts(getHostsParams)
+	if err != nil {
+		return nil, err
+	}
+	return hostsResult.Payload.Elements, nil
+}
+
+// filterEligibleHosts returns the unassigned hosts that match the host selector, sorted by FQDN.
+// Hosts without a compatible storage type, e.g. hosts commissioned by older VCF versions, are
+// compatible with every storage type.
+func filterEligibleHosts(hostsList []*models.Host, selector map[string]interface{},
+	defaultStorageType string) ([]*models.Host, error) {
+	storageType := defaultStorageType
+	if selectorStorageType, ok := selector["storage_type"]; ok && !validationutils.IsEmpty(selectorStorageType) {
+		storageType = selectorStorageType.(string)
+	}
+	var fqdnPattern *regexp.Regexp
+	if fqdnPatternRaw, ok := selector["fqdn_pattern"]; ok && !validationutils.IsEmpty(fqdnPatternRaw) {
+		var err error
+		fqdnPattern, err = regexp.Compile(fqdnPatternRaw.(string))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var result []*models.Host
+	for _, hostObj := range hostsList {
+		if hostObj == nil || hostObj.Status != HostStatusUnassignedUseable {
+			continue
+		}
+		if len(storageType) > 0 && len(hostObj.CompatibleStorageType) > 0 &&
+			!strings.EqualFold(hostObj.CompatibleStorageType, storageType) {
+			continue
+		}
+		if fqdnPattern != nil && !fqdnPattern.MatchString(hostObj.Fqdn) {
+			continue
+		}
+		result = append(result, hostObj)
+	}
+	// Sort for reproducibility
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Fqdn < result[j].Fqdn
+	})
+	return result, nil
+}
+
+func hostFqdn(hostRaw interface{}) string {
+	host, _ := hostRaw.(map[string]interface{})
+	if fqdn, _ := host["fqdn"].(string); len(fqdn) > 0 {
+		return fqdn
+	}
+	hostName, _ := host["host_name"].(string)
+	return hostName
+}
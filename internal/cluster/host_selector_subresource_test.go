@@ -0,0 +1,112 @@
+/*
+ *  Copyright 2023 VMware, Inc.
+ *    SPDX-License-Identifier: MPL-2.0
+ */
+
+package cluster
+
+import (
+	"errors"
+	"github.com/vmware/vcf-sdk-go/models"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSelectHosts(t *testing.T) {
+	freePoolHosts := []*models.Host{
+		{ID: "host-3", Fqdn: "esxi-3.vrack.vsphere.local", Status: HostStatusUnassignedUseable, CompatibleStorageType: "VSAN"},
+		{ID: "host-1", Fqdn: "esxi-1.vrack.vsphere.local", Status: HostStatusUnassignedUseable, CompatibleStorageType: "VSAN",
+			IPAddresses: []*models.IPAddress{{IPAddress: "10.0.0.101", Type: "MANAGEMENT"}}},
+		{ID: "host-2", Fqdn: "esxi-2.vrack.vsphere.local", Status: HostStatusUnassignedUseable, CompatibleStorageType: "NFS"},
+		{ID: "host-4", Fqdn: "esxi-4.vrack.vsphere.local", Status: HostStatusAssigned, CompatibleStorageType: "VSAN"},
+		// hosts commissioned without a storage type are compatible with every storage type
+		{ID: "host-5", Fqdn: "esxi-5.vrack.vsphere.local", Status: HostStatusUnassignedUseable},
+		nil,
+	}
+	selectedHosts := []interface{}{
+		map[string]interface{}{"id": "host-7", "host_name": "esxi-7.vrack.vsphere.local"},
+		map[string]interface{}{"id": "host-6", "host_name": "esxi-6.vrack.vsphere.local"},
+	}
+
+	var selectorTests = []struct {
+		name               string
+		selector           map[string]interface{}
+		selectedHosts      []interface{}
+		defaultStorageType string
+		expectedHostIds    []string
+		expectedErr        string
+	}{
+		{"default storage type", map[string]interface{}{"count": 3}, nil, "VSAN",
+			[]string{"host-1", "host-3", "host-5"}, ""},
+		{"selector storage type", map[string]interface{}{"count": 2, "storage_type": "nfs"}, nil, "VSAN",
+			[]string{"host-2", "host-5"}, ""},
+		{"fqdn pattern", map[string]interface{}{"count": 2, "fqdn_pattern": "esxi-[35]"}, nil, "VSAN",
+			[]string{"host-3", "host-5"}, ""},
+		{"keep selected hosts", map[string]interface{}{"count": 3}, selectedHosts, "VSAN",
+			[]string{"host-6", "host-7", "host-1"}, ""},
+		{"release hosts", map[string]interface{}{"count": 1}, selectedHosts, "VSAN",
+			[]string{"host-6"}, ""},
+		{"not enough hosts", map[string]interface{}{"count": 4}, nil, "VSAN",
+			nil, "4 more needed, 3 found"},
+		{"invalid fqdn pattern", map[string]interface{}{"count": 2, "fqdn_pattern": "esxi-["}, nil, "VSAN",
+			nil, "missing closing ]"},
+	}
+	for _, selectorTest := range selectorTests {
+		result, err := selectHosts(selectorTest.selector, selectorTest.selectedHosts, selectorTest.defaultStorageType,
+			func() ([]*models.Host, error) { return freePoolHosts, nil })
+		if len(selectorTest.expectedErr) > 0 {
+			if err == nil || !strings.Contains(err.Error(), selectorTest.expectedErr) {
+				t.Errorf("%s: expected error containing %q, got %v", selectorTest.name, selectorTest.expectedErr, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", selectorTest.name, err)
+			continue
+		}
+		var hostIds []string
+		for _, hostRaw := range result {
+			hostIds = append(hostIds, hostRaw.(map[string]interface{})["id"].(string))
+		}
+		if !reflect.DeepEqual(hostIds, selectorTest.expectedHostIds) {
+			t.Errorf("%s: expected hosts %v, got %v", selectorTest.name, selectorTest.expectedHostIds, hostIds)
+		}
+	}
+}
+
+func TestSelectHostsAttributes(t *testing.T) {
+	freePoolHosts := []*models.Host{
+		{ID: "host-1", Fqdn: "esxi-1.vrack.vsphere.local", Status: HostStatusUnassignedUseable,
+			IPAddresses: []*models.IPAddress{{IPAddress: "10.0.0.101", Type: "MANAGEMENT"}}},
+	}
+	vmnics := []interface{}{map[string]interface{}{"id": "vmnic0", "vds_name": "sfo-w01-cl01-vds01"}}
+	selector := map[string]interface{}{
+		"count":                  1,
+		"availability_zone_name": "az1",
+		"license_key":            "XX0XX-XX0XX",
+		"vmnic":                  vmnics,
+	}
+	result, err := selectHosts(selector, nil, "", func() ([]*models.Host, error) { return freePoolHosts, nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedHost := map[string]interface{}{
+		"id":                     "host-1",
+		"host_name":              "esxi-1.vrack.vsphere.local",
+		"ip_address":             "10.0.0.101",
+		"availability_zone_name": "az1",
+		"license_key":            "XX0XX-XX0XX",
+		"vmnic":                  vmnics,
+	}
+	if len(result) != 1 || !reflect.DeepEqual(result[0], expectedHost) {
+		t.Errorf("expected the selected host %v, got %v", expectedHost, result)
+	}
+
+	// the free pool is not read if enough hosts are selected
+	_, err = selectHosts(selector, []interface{}{map[string]interface{}{"id": "host-2"}}, "",
+		func() ([]*models.Host, error) { return nil, errors.New("unexpected call") })
+	if err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+}
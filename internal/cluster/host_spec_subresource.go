@@ -6,12 +6,25 @@
 package cluster
 
 import (
+	"context"
 	"fmt"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/vmware/terraform-provider-vcf/internal/constants"
 	"github.com/vmware/terraform-provider-vcf/internal/network"
+	"github.com/vmware/terraform-provider-vcf/internal/resource_utils"
 	validationutils "github.com/vmware/terraform-provider-vcf/internal/validation"
+	"github.com/vmware/vcf-sdk-go/client"
+	"github.com/vmware/vcf-sdk-go/client/hosts"
 	"github.com/vmware/vcf-sdk-go/models"
+	"strings"
+)
+
+const (
+	// HostStatusUnassignedUseable the status of the commissioned hosts in the free pool.
+	HostStatusUnassignedUseable = "UNASSIGNED_USEABLE"
+	// HostStatusAssigned the status of the hosts that are assigned to a cluster.
+	HostStatusAssigned = "ASSIGNED"
 )
 
 // HostSpecSchema this helper function extracts the Host
@@ -21,24 +34,35 @@ func HostSpecSchema() *schema.Resource {
 		Schema: map[string]*schema.Schema{
 			"id": {
 				Type:        schema.TypeString,
-				Required:    true,
-				Description: "ID of the ESXi host in the free pool",
+				Optional:    true,
+				Computed:    true,
+				Description: "ID of the ESXi host in the free pool. Either id or fqdn is required",
+			},
+			"fqdn": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Description: "Fully qualified domain name of a commissioned ESXi host in the free pool, that is " +
+					"resolved to its ID. Either id or fqdn is required",
+				ValidateFunc: validation.NoZeroValues,
 			},
 			"host_name": {
 				Type:         schema.TypeString,
 				Optional:     true,
+				Computed:     true,
 				Description:  "Host name of the ESXi host",
 				ValidateFunc: validation.NoZeroValues,
 			},
 			"availability_zone_name": {
 				Type:         schema.TypeString,
 				Optional:     true,
+				Computed:     true,
 				Description:  "Availability Zone Name. This is required while performing a stretched cluster expand operation",
 				ValidateFunc: validation.NoZeroValues,
 			},
 			"ip_address": {
 				Type:         schema.TypeString,
 				Optional:     true,
+				Computed:     true,
 				Description:  "IPv4 address of the ESXi host",
 				ValidateFunc: validationutils.ValidateIPv4AddressSchema,
 			},
@@ -57,10 +81,22 @@ func HostSpecSchema() *schema.Resource {
 				ValidateFunc: validation.NoZeroValues,
 			},
 			"password": {
-				Type:         schema.TypeString,
-				Optional:     true,
-				Sensitive:    true,
-				Description:  "Password to authenticate to the ESXi host",
+				Type:      schema.TypeString,
+				Optional:  true,
+				Sensitive: true,
+				Description: "Password to authenticate to the ESXi host. The password is only used when the host is " +
+					"added to the cluster, the state holds a salted hash of it unless password_version is set",
+				ValidateFunc:     validation.NoZeroValues,
+				StateFunc:        resource_utils.HashPasswordStateFunc,
+				DiffSuppressFunc: suppressHostSpecPasswordDiff,
+			},
+			"password_version": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Description: "Handles the password as write-only if set. The password is removed from the state, " +
+					"not even its hash is kept, once the host is added, and later changes of the password are " +
+					"ignored. The version marker is only stored in the state, the password of a host in a cluster " +
+					"is changed with vcf_host",
 				ValidateFunc: validation.NoZeroValues,
 			},
 			"serial_number": {
@@ -70,12 +106,42 @@ func HostSpecSchema() *schema.Resource {
 				ValidateFunc: validation.NoZeroValues,
 			},
 			"ssh_thumbprint": {
-				Type:         schema.TypeString,
-				Optional:     true,
-				Sensitive:    true,
-				Description:  "SSH thumbprint of the ESXi host",
+				Type:      schema.TypeString,
+				Optional:  true,
+				Computed:  true,
+				Sensitive: true,
+				Description: "SSH thumbprint of the ESXi host. Computed if discover_ssh_thumbprint is set and " +
+					"the thumbprint is not configured, in which case it is known only after apply",
+				ValidateFunc: validation.NoZeroValues,
+			},
+			"discover_ssh_thumbprint": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+				Description: "Discovers the SSH thumbprint of the ESXi host when it is added and pins it in the " +
+					"state (trust on first use). The thumbprint is discovered during apply, right before the hosts " +
+					"are added, so the plan doesn't show it. The thumbprint is verified when the hosts of the " +
+					"cluster change afterwards, a mismatch with the pinned thumbprint fails the apply",
+			},
+			"ssl_thumbprint": {
+				Type:      schema.TypeString,
+				Optional:  true,
+				Computed:  true,
+				Sensitive: true,
+				Description: "SSL thumbprint of the ESXi host. SDDC Manager doesn't accept it when the host is added, " +
+					"the provider verifies the certificate of the host against it before the hosts of the cluster " +
+					"change. Computed if discover_ssl_thumbprint is set and the thumbprint is not configured, in " +
+					"which case it is known only after apply",
 				ValidateFunc: validation.NoZeroValues,
 			},
+			"discover_ssl_thumbprint": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+				Description: "Discovers the SSL thumbprint of the ESXi host when it is added and pins it in the " +
+					"state (trust on first use), in the same way as discover_ssh_thumbprint. A mismatch with the " +
+					"pinned thumbprint fails the apply",
+			},
 			"vmnic": {
 				Type:        schema.TypeList,
 				Optional:    true,
@@ -113,14 +179,49 @@ func FlattenHost(host *models.Host) *map[string]interface{} {
 	return &result
 }
 
+// suppressHostSpecPasswordDiff ignores the changes of the write-only passwords of the hosts that are
+// already added, and the passwords that match the hash in the state.
+func suppressHostSpecPasswordDiff(key, oldValue, newValue string, data *schema.ResourceData) bool {
+	hostKeyPrefix := strings.TrimSuffix(key, "password")
+	if passwordVersion, _ := data.Get(hostKeyPrefix + "password_version").(string); len(passwordVersion) > 0 {
+		if oldHostId, _ := data.GetChange(hostKeyPrefix + "id"); len(oldHostId.(string)) > 0 {
+			return true
+		}
+	}
+	return resource_utils.SuppressMatchingPasswordDiff(key, oldValue, newValue, data)
+}
+
+// HashHostPasswords replaces the passwords in a list of hosts with their hashes before the list is set
+// to the state. The write-only passwords of the hosts with a password_version are removed instead.
+func HashHostPasswords(hostsList []interface{}) []interface{} {
+	for _, hostRaw := range hostsList {
+		if host, ok := hostRaw.(map[string]interface{}); ok {
+			hashHostPassword(host)
+		}
+	}
+	return hostsList
+}
+
+func hashHostPassword(host map[string]interface{}) {
+	if passwordVersion, _ := host["password_version"].(string); len(passwordVersion) > 0 {
+		host["password"] = ""
+		return
+	}
+	host["password"] = resource_utils.HashPasswordStateFunc(host["password"])
+}
+
 func TryConvertToHostSpec(object map[string]interface{}) (*models.HostSpec, error) {
 	result := &models.HostSpec{}
 	if object == nil {
 		return nil, fmt.Errorf("cannot convert to HostSpec, object is nil")
 	}
-	id := object["id"].(string)
+	id, _ := object["id"].(string)
 	if len(id) == 0 {
-		return nil, fmt.Errorf("cannot convert to HostSpec, id is required")
+		fqdn, _ := object["fqdn"].(string)
+		if len(fqdn) > 0 {
+			return nil, fmt.Errorf("cannot convert to HostSpec, the ID of host %q is not resolved", fqdn)
+		}
+		return nil, fmt.Errorf("cannot convert to HostSpec, either id or fqdn is required")
 	}
 	result.ID = &id
 	if hostName, ok := object["host_name"]; ok && !validationutils.IsEmpty(hostName) {
@@ -166,3 +267,184 @@ func TryConvertToHostSpec(object map[string]interface{}) (*models.HostSpec, erro
 
 	return result, nil
 }
+
+// ResolveHostIds sets the ID of the hosts in hostsList that are referenced only by FQDN.
+// Hosts that are already in existingHostsList or in the cluster with the provided ID keep their ID,
+// the other hosts have to be in the free pool and, if storageType is not empty, compatible with the
+// principal storage of the cluster.
+func ResolveHostIds(ctx context.Context, hostsList, existingHostsList []interface{}, clusterId, storageType string,
+	apiClient *client.VcfClient) error {
+	existingHostIds := make(map[string]string)
+	for _, existingHostRaw := range existingHostsList {
+		existingHost, ok := existingHostRaw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		existingHostId, _ := existingHost["id"].(string)
+		for _, attributeName := range []string{"fqdn", "host_name"} {
+			if fqdn, _ := existingHost[attributeName].(string); len(fqdn) > 0 && len(existingHostId) > 0 {
+				existingHostIds[strings.ToLower(fqdn)] = existingHostId
+			}
+		}
+	}
+
+	var allHostsByFqdn map[string]*models.Host
+	for _, hostRaw := range hostsList {
+		host := hostRaw.(map[string]interface{})
+		fqdn, _ := host["fqdn"].(string)
+		if id, _ := host["id"].(string); len(id) > 0 || len(fqdn) == 0 {
+			continue
+		}
+		if existingHostId, ok := existingHostIds[strings.ToLower(fqdn)]; ok {
+			host["id"] = existingHostId
+			continue
+		}
+
+		if allHostsByFqdn == nil {
+			getHostsParams := hosts.NewGetHostsParamsWithContext(ctx).
+				WithTimeout(constants.DefaultVcfApiCallTimeout)
+			hostsResult, err := apiClient.Hosts.GetHosts(getHostsParams)
+			if err != nil {
+				return err
+			}
+			allHostsByFqdn = make(map[string]*models.Host, len(hostsResult.Payload.Elements))
+			for _, hostObj := range hostsResult.Payload.Elements {
+				if hostObj != nil {
+					allHostsByFqdn[strings.ToLower(hostObj.Fqdn)] = hostObj
+				}
+			}
+		}
+
+		hostObj, ok := allHostsByFqdn[strings.ToLower(fqdn)]
+		if !ok {
+			return fmt.Errorf("host %q is not commissioned", fqdn)
+		}
+		if len(clusterId) > 0 && hostObj.Cluster != nil && hostObj.Cluster.ID != nil && *hostObj.Cluster.ID == clusterId {
+			host["id"] = hostObj.ID
+			continue
+		}
+		if hostObj.Status != HostStatusUnassignedUseable {
+			return fmt.Errorf("host %q cannot be used, its status is %s instead of %s", fqdn,
+				hostObj.Status, HostStatusUnassignedUseable)
+		}
+		if len(storageType) > 0 && len(hostObj.CompatibleStorageType) > 0 &&
+			!strings.EqualFold(hostObj.CompatibleStorageType, storageType) {
+			return fmt.Errorf("host %q is commissioned with storage type %s, which doesn't match the %s "+
+				"principal storage of the cluster", fqdn, hostObj.CompatibleStorageType, storageType)
+		}
+		host["id"] = hostObj.ID
+	}
+	return nil
+}
+
+// hostSpecThumbprints the thumbprint attributes of the host specs, with the flags that turn on their discovery.
+var hostSpecThumbprints = []struct {
+	attributeName     string
+	discoverAttribute string
+	thumbprintType    string
+	discover          func(ctx context.Context, address string) (string, error)
+}{
+	{"ssh_thumbprint", "discover_ssh_thumbprint", "SSH", network.GetSshThumbprint},
+	{"ssl_thumbprint", "discover_ssl_thumbprint", "SSL", network.GetSslThumbprint},
+}
+
+// PinHostThumbprints discovers the SSH and SSL thumbprints of the hosts in hostsList that have
+// discover_ssh_thumbprint or discover_ssl_thumbprint set. A discovered thumbprint is pinned if neither
+// the host nor the host with the same ID in existingHostsList has one, otherwise it has to match the
+// pinned thumbprint. It runs during apply, together with the resolution of the host IDs, as the hosts
+// are nested in sets whose blocks can't be read at plan time. The discovered thumbprints are known only
+// after apply and a mismatch fails the apply before any host is added.
+func PinHostThumbprints(ctx context.Context, hostsList, existingHostsList []interface{}) error {
+	for _, thumbprint := range hostSpecThumbprints {
+		pinnedThumbprints := getPinnedThumbprints(existingHostsList, thumbprint.attributeName)
+
+		for _, hostRaw := range hostsList {
+			host := hostRaw.(map[string]interface{})
+			if discover, _ := host[thumbprint.discoverAttribute].(bool); !discover {
+				continue
+			}
+			var address string
+			for _, attributeName := range []string{"fqdn", "host_name", "ip_address"} {
+				if address, _ = host[attributeName].(string); len(address) > 0 {
+					break
+				}
+			}
+			if len(address) == 0 {
+				return fmt.Errorf("cannot discover the %s thumbprint of host %q, fqdn, host_name or ip_address "+
+					"is required", thumbprint.thumbprintType, host["id"])
+			}
+
+			discoveredThumbprint, err := thumbprint.discover(ctx, address)
+			if err != nil {
+				return err
+			}
+			pinnedThumbprint, _ := host[thumbprint.attributeName].(string)
+			if len(pinnedThumbprint) == 0 {
+				hostId, _ := host["id"].(string)
+				pinnedThumbprint = pinnedThumbprints[hostId]
+			}
+			if len(pinnedThumbprint) == 0 {
+				host[thumbprint.attributeName] = discoveredThumbprint
+				continue
+			}
+			err = network.VerifyPinnedThumbprint(thumbprint.thumbprintType, address, pinnedThumbprint, discoveredThumbprint)
+			if err != nil {
+				return err
+			}
+			host[thumbprint.attributeName] = pinnedThumbprint
+		}
+	}
+	return nil
+}
+
+// KeepPinnedThumbprints sets the SSH and SSL thumbprints pinned for the hosts in existingHostsList to the
+// hosts with the same ID in hostsList that have no thumbprint, without connecting to the hosts.
+func KeepPinnedThumbprints(hostsList, existingHostsList []interface{}) {
+	for _, thumbprint := range hostSpecThumbprints {
+		pinnedThumbprints := getPinnedThumbprints(existingHostsList, thumbprint.attributeName)
+		for _, hostRaw := range hostsList {
+			host := hostRaw.(map[string]interface{})
+			hostId, _ := host["id"].(string)
+			if value, _ := host[thumbprint.attributeName].(string); len(value) == 0 && len(pinnedThumbprints[hostId]) > 0 {
+				host[thumbprint.attributeName] = pinnedThumbprints[hostId]
+			}
+		}
+	}
+}
+
+// getPinnedThumbprints returns the thumbprints in the provided attribute of the hosts that have one, indexed by host ID.
+func getPinnedThumbprints(hostsList []interface{}, attributeName string) map[string]string {
+	result := make(map[string]string)
+	for _, hostRaw := range hostsList {
+		host, ok := hostRaw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		hostId, _ := host["id"].(string)
+		if thumbprint, _ := host[attributeName].(string); len(hostId) > 0 && len(thumbprint) > 0 {
+			result[hostId] = thumbprint
+		}
+	}
+	return result
+}
+
+// GetPrincipalStorageType returns the storage type, as used for commissioning hosts, of the principal
+// storage configured in a cluster. An empty string is returned if no datastore is configured.
+func GetPrincipalStorageType(object map[string]interface{}) string {
+	storageTypes := []struct {
+		attributeName string
+		storageType   string
+	}{
+		{"vsan_datastore", "VSAN"},
+		{"vsan_remote_datastore_cluster", "VSAN_REMOTE"},
+		{"vmfs_datastore", "VMFS_FC"},
+		{"nfs_datastores", "NFS"},
+		{"vvol_datastores", "VVOL"},
+	}
+	for _, entry := range storageTypes {
+		if datastores, ok := object[entry.attributeName]; ok && !validationutils.IsEmpty(datastores) {
+			return entry.storageType
+		}
+	}
+	return ""
+}
@@ -0,0 +1,62 @@
+/*
+ *  Copyright 2023 VMware, Inc.
+ *    SPDX-License-Identifier: MPL-2.0
+ */
+
+package cluster
+
+import (
+	"github.com/vmware/terraform-provider-vcf/internal/resource_utils"
+	"testing"
+)
+
+func TestHashHostPasswords(t *testing.T) {
+	hostsList := []interface{}{
+		map[string]interface{}{"id": "host-1", "password": "VMware1!"},
+		map[string]interface{}{"id": "host-2", "password": "VMware1!", "password_version": "1"},
+		map[string]interface{}{"id": "host-3", "password": ""},
+	}
+
+	HashHostPasswords(hostsList)
+
+	hashedPassword := hostsList[0].(map[string]interface{})["password"].(string)
+	if !resource_utils.IsPasswordHash(hashedPassword) || !resource_utils.PasswordMatchesHash("VMware1!", hashedPassword) {
+		t.Errorf("expected the hash of the password, got %q", hashedPassword)
+	}
+	if writeOnlyPassword := hostsList[1].(map[string]interface{})["password"]; writeOnlyPassword != "" {
+		t.Errorf("expected the write-only password to be removed, got %q", writeOnlyPassword)
+	}
+	if emptyPassword := hostsList[2].(map[string]interface{})["password"]; emptyPassword != "" {
+		t.Errorf("expected the empty password to be kept, got %q", emptyPassword)
+	}
+}
+
+func TestKeepPinnedThumbprints(t *testing.T) {
+	existingHostsList := []interface{}{
+		map[string]interface{}{"id": "host-1", "ssh_thumbprint": "SHA256:c3NoLTE", "ssl_thumbprint": "AB:CD:01"},
+		map[string]interface{}{"id": "host-2", "ssh_thumbprint": "SHA256:c3NoLTI", "ssl_thumbprint": ""},
+	}
+	hostsList := []interface{}{
+		map[string]interface{}{"id": "host-1", "ssh_thumbprint": "", "ssl_thumbprint": ""},
+		map[string]interface{}{"id": "host-2", "ssh_thumbprint": "SHA256:Y29uZmlndXJlZA", "ssl_thumbprint": ""},
+		map[string]interface{}{"id": "host-3", "ssh_thumbprint": "", "ssl_thumbprint": ""},
+	}
+
+	KeepPinnedThumbprints(hostsList, existingHostsList)
+
+	var expectedThumbprints = []struct {
+		sshThumbprint string
+		sslThumbprint string
+	}{
+		{"SHA256:c3NoLTE", "AB:CD:01"},
+		{"SHA256:Y29uZmlndXJlZA", ""},
+		{"", ""},
+	}
+	for i, expected := range expectedThumbprints {
+		host := hostsList[i].(map[string]interface{})
+		if host["ssh_thumbprint"] != expected.sshThumbprint || host["ssl_thumbprint"] != expected.sslThumbprint {
+			t.Errorf("host %s: expected thumbprints %q and %q, got %q and %q", host["id"],
+				expected.sshThumbprint, expected.sslThumbprint, host["ssh_thumbprint"], host["ssl_thumbprint"])
+		}
+	}
+}
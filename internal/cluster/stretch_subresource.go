@@ -0,0 +1,151 @@
+/*
+ *  Copyright 2023 VMware, Inc.
+ *    SPDX-License-Identifier: MPL-2.0
+ */
+
+package cluster
+
+import (
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	validationutils "github.com/vmware/terraform-provider-vcf/internal/validation"
+	"github.com/vmware/vcf-sdk-go/models"
+)
+
+// StretchSchema this helper function extracts the vSAN stretched cluster schema.
+func StretchSchema() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"witness_host_fqdn": {
+				Type:         schema.TypeString,
+				Required:     true,
+				Description:  "Fully qualified domain name of the vSAN witness host",
+				ValidateFunc: validation.NoZeroValues,
+			},
+			"witness_host_vsan_ip": {
+				Type:         schema.TypeString,
+				Required:     true,
+				Description:  "IPv4 address of the vSAN VMkernel adapter of the witness host",
+				ValidateFunc: validationutils.ValidateIPv4AddressSchema,
+			},
+			"witness_host_vsan_cidr": {
+				Type:         schema.TypeString,
+				Required:     true,
+				Description:  "CIDR of the vSAN network of the witness host, e.g. 172.18.0.0/24",
+				ValidateFunc: validation.IsCIDR,
+			},
+			"witness_traffic_shared_with_vsan_traffic": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+				Description: "Whether the witness traffic uses the vSAN VMkernel adapters of the hosts. " +
+					"If false the witness traffic is separated to the management VMkernel adapters",
+			},
+			"secondary_az_overlay_vlan_id": {
+				Type:         schema.TypeInt,
+				Required:     true,
+				Description:  "VLAN ID of the NSX overlay network in the secondary availability zone",
+				ValidateFunc: validation.IntBetween(0, 4095),
+			},
+			"is_edge_cluster_configured_for_multi_az": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Whether the NSX Edge cluster of the domain is configured for multiple availability zones",
+			},
+			"secondary_az_host": {
+				Type:     schema.TypeList,
+				Required: true,
+				MinItems: 1,
+				Description: "List of ESXi host information from the free pool to add to the secondary " +
+					"availability zone. The availability_zone_name of each host is required",
+				Elem: HostSpecSchema(),
+			},
+			"vsan_network": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Description: "vSAN networks of the availability zones, used to route traffic between them",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"vsan_cidr": {
+							Type:         schema.TypeString,
+							Required:     true,
+							Description:  "CIDR of the vSAN network, e.g. 172.18.0.0/24",
+							ValidateFunc: validation.IsCIDR,
+						},
+						"vsan_gateway_ip": {
+							Type:         schema.TypeString,
+							Required:     true,
+							Description:  "IPv4 address of the gateway of the vSAN network",
+							ValidateFunc: validationutils.ValidateIPv4AddressSchema,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// TryConvertToClusterStretchSpec is a convenience method that converts a map[string]interface{}
+// received from the Terraform SDK to an API struct, used in VCF API calls.
+func TryConvertToClusterStretchSpec(object map[string]interface{}) (*models.ClusterStretchSpec, error) {
+	if object == nil {
+		return nil, fmt.Errorf("cannot convert to ClusterStretchSpec, object is nil")
+	}
+	result := &models.ClusterStretchSpec{}
+	result.WitnessSpec = TryConvertToWitnessSpec(object)
+	result.WitnessTrafficSharedWithVSANTraffic = object["witness_traffic_shared_with_vsan_traffic"].(bool)
+	result.IsEdgeClusterConfiguredForMultiAZ = object["is_edge_cluster_configured_for_multi_az"].(bool)
+	secondaryAzOverlayVlanId := int32(object["secondary_az_overlay_vlan_id"].(int))
+	result.SecondaryAzOverlayVlanID = &secondaryAzOverlayVlanId
+
+	hostsList := object["secondary_az_host"].([]interface{})
+	if len(hostsList) == 0 {
+		return nil, fmt.Errorf("cannot convert to ClusterStretchSpec, secondary_az_host list is empty")
+	}
+	for _, hostListEntry := range hostsList {
+		hostSpec, err := TryConvertToHostSpec(hostListEntry.(map[string]interface{}))
+		if err != nil {
+			return nil, err
+		}
+		if len(hostSpec.AzName) == 0 {
+			return nil, fmt.Errorf("cannot convert to ClusterStretchSpec, availability_zone_name is required for host %q",
+				*hostSpec.ID)
+		}
+		result.HostSpecs = append(result.HostSpecs, hostSpec)
+	}
+
+	result.VSANNetworkSpecs = TryConvertToVsanNetworkSpecs(object)
+
+	return result, nil
+}
+
+// TryConvertToWitnessSpec creates the witness host specification from the stretch configuration.
+func TryConvertToWitnessSpec(object map[string]interface{}) *models.WitnessSpec {
+	fqdn := object["witness_host_fqdn"].(string)
+	vsanIp := object["witness_host_vsan_ip"].(string)
+	vsanCidr := object["witness_host_vsan_cidr"].(string)
+	return &models.WitnessSpec{
+		Fqdn:     &fqdn,
+		VSANIP:   &vsanIp,
+		VSANCidr: &vsanCidr,
+	}
+}
+
+// TryConvertToVsanNetworkSpecs creates the vSAN network specifications from the stretch configuration.
+func TryConvertToVsanNetworkSpecs(object map[string]interface{}) []*models.VSANNetworkSpec {
+	var result []*models.VSANNetworkSpec
+	vsanNetworksRaw, ok := object["vsan_network"]
+	if !ok {
+		return result
+	}
+	for _, vsanNetworkRaw := range vsanNetworksRaw.([]interface{}) {
+		vsanNetwork := vsanNetworkRaw.(map[string]interface{})
+		result = append(result, &models.VSANNetworkSpec{
+			VSANCidr:      vsanNetwork["vsan_cidr"].(string),
+			VSANGatewayIP: vsanNetwork["vsan_gateway_ip"].(string),
+		})
+	}
+	return result
+}
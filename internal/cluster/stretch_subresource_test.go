@@ -0,0 +1,145 @@
+/*
+ *  Copyright 2023 VMware, Inc.
+ *    SPDX-License-Identifier: MPL-2.0
+ */
+
+package cluster
+
+import (
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/vmware/terraform-provider-vcf/internal/resource_utils"
+	"strings"
+	"testing"
+)
+
+func testStretch(secondaryAzHosts ...interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"witness_host_fqdn":                        "witness.vrack.vsphere.local",
+		"witness_host_vsan_ip":                     "10.0.22.10",
+		"witness_host_vsan_cidr":                   "10.0.22.0/24",
+		"witness_traffic_shared_with_vsan_traffic": true,
+		"secondary_az_overlay_vlan_id":             2500,
+		"is_edge_cluster_configured_for_multi_az":  false,
+		"secondary_az_host":                        secondaryAzHosts,
+		"vsan_network": []interface{}{
+			map[string]interface{}{"vsan_cidr": "10.0.23.0/24", "vsan_gateway_ip": "10.0.23.1"},
+		},
+	}
+}
+
+func TestTryConvertToClusterStretchSpec(t *testing.T) {
+	stretchSpec, err := TryConvertToClusterStretchSpec(testStretch(
+		map[string]interface{}{"id": "host-4", "availability_zone_name": "az2", "license_key": "XX0XX-XX0XX"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *stretchSpec.WitnessSpec.Fqdn != "witness.vrack.vsphere.local" || *stretchSpec.WitnessSpec.VSANIP != "10.0.22.10" ||
+		*stretchSpec.WitnessSpec.VSANCidr != "10.0.22.0/24" {
+		t.Errorf("unexpected witness spec %+v", stretchSpec.WitnessSpec)
+	}
+	if !stretchSpec.WitnessTrafficSharedWithVSANTraffic || stretchSpec.IsEdgeClusterConfiguredForMultiAZ ||
+		*stretchSpec.SecondaryAzOverlayVlanID != 2500 {
+		t.Errorf("unexpected stretch spec %+v", stretchSpec)
+	}
+	if len(stretchSpec.HostSpecs) != 1 || *stretchSpec.HostSpecs[0].ID != "host-4" ||
+		stretchSpec.HostSpecs[0].AzName != "az2" || stretchSpec.HostSpecs[0].LicenseKey != "XX0XX-XX0XX" {
+		t.Errorf("unexpected host specs %+v", stretchSpec.HostSpecs)
+	}
+	if len(stretchSpec.VSANNetworkSpecs) != 1 || stretchSpec.VSANNetworkSpecs[0].VSANCidr != "10.0.23.0/24" ||
+		stretchSpec.VSANNetworkSpecs[0].VSANGatewayIP != "10.0.23.1" {
+		t.Errorf("unexpected vSAN network specs %+v", stretchSpec.VSANNetworkSpecs)
+	}
+
+	var errorTests = []struct {
+		name        string
+		stretch     map[string]interface{}
+		expectedErr string
+	}{
+		{"nil stretch", nil, "object is nil"},
+		{"no hosts", testStretch(), "secondary_az_host list is empty"},
+		{"no availability zone", testStretch(map[string]interface{}{"id": "host-4"}),
+			"availability_zone_name is required for host \"host-4\""},
+		{"unresolved host", testStretch(map[string]interface{}{"fqdn": "esxi-4.vrack.vsphere.local",
+			"availability_zone_name": "az2"}), "the ID of host \"esxi-4.vrack.vsphere.local\" is not resolved"},
+	}
+	for _, errorTest := range errorTests {
+		_, err = TryConvertToClusterStretchSpec(errorTest.stretch)
+		if err == nil || !strings.Contains(err.Error(), errorTest.expectedErr) {
+			t.Errorf("%s: expected error containing %q, got %v", errorTest.name, errorTest.expectedErr, err)
+		}
+	}
+}
+
+func TestCreateClusterUnstretchUpdateSpecs(t *testing.T) {
+	updateSpecs := CreateClusterUnstretchUpdateSpecs(testStretch(
+		map[string]interface{}{"id": "host-4"}, map[string]interface{}{"id": "host-5"}), true)
+	if len(updateSpecs) != 2 {
+		t.Fatalf("expected a contraction and an unstretch spec, got %d specs", len(updateSpecs))
+	}
+	compactionSpec := updateSpecs[0].ClusterCompactionSpec
+	if compactionSpec == nil || len(compactionSpec.Hosts) != 2 || compactionSpec.Hosts[0].ID != "host-4" ||
+		compactionSpec.Hosts[1].ID != "host-5" {
+		t.Fatalf("expected the secondary availability zone hosts to be removed first, got %+v", updateSpecs[0])
+	}
+	if !compactionSpec.Force || !compactionSpec.ForceByPassingSafeMinSize {
+		t.Errorf("expected the removal of the hosts to be forced")
+	}
+	if updateSpecs[1].ClusterUnstretchSpec == nil || updateSpecs[1].ClusterCompactionSpec != nil {
+		t.Errorf("expected the cluster to be unstretched last, got %+v", updateSpecs[1])
+	}
+
+	updateSpecs = CreateClusterUnstretchUpdateSpecs(testStretch(), false)
+	if len(updateSpecs) != 1 || updateSpecs[0].ClusterUnstretchSpec == nil {
+		t.Errorf("expected only an unstretch spec without secondary availability zone hosts, got %+v", updateSpecs)
+	}
+}
+
+func TestValidateClusterStretchChange(t *testing.T) {
+	twoHosts := schema.NewSet(resource_utils.HashByKey("fqdn", "id"), []interface{}{
+		map[string]interface{}{"id": "host-1"}, map[string]interface{}{"id": "host-2"},
+	})
+	stretchOneHost := []interface{}{testStretch(map[string]interface{}{"id": "host-4"})}
+	stretchTwoHosts := []interface{}{testStretch(map[string]interface{}{"id": "host-4"}, map[string]interface{}{"id": "host-5"})}
+	vsanDatastore := []interface{}{map[string]interface{}{"datastore_name": "vsan-ds"}}
+
+	var stretchTests = []struct {
+		name          string
+		changed       bool
+		oldStretch    []interface{}
+		newStretch    []interface{}
+		vsanDatastore []interface{}
+		expectedErr   string
+	}{
+		{"unchanged", false, stretchTwoHosts, stretchOneHost, vsanDatastore, ""},
+		{"stretch", true, []interface{}{}, stretchTwoHosts, vsanDatastore, ""},
+		{"unstretch", true, stretchTwoHosts, []interface{}{}, vsanDatastore, ""},
+		{"modify", true, stretchOneHost, stretchTwoHosts, vsanDatastore, "modifying the stretch configuration"},
+		{"not vSAN", true, []interface{}{}, stretchTwoHosts, []interface{}{}, "only clusters with vSAN primary storage"},
+		{"empty stretch", true, []interface{}{}, []interface{}{nil}, vsanDatastore, "is empty"},
+		{"hosts count", true, []interface{}{}, stretchOneHost, vsanDatastore,
+			"the number of hosts in the secondary availability zone (1) must be equal to the number of hosts in the cluster (2)"},
+	}
+	for _, stretchTest := range stretchTests {
+		values := map[string]interface{}{
+			"name":           "sfo-w01-cl01",
+			"host":           twoHosts,
+			"vsan_datastore": stretchTest.vsanDatastore,
+			"stretch":        stretchTest.newStretch,
+		}
+		getChange := func(key string) (interface{}, interface{}) {
+			if key == "stretch" {
+				return stretchTest.oldStretch, stretchTest.newStretch
+			}
+			return values[key], values[key]
+		}
+		err := ValidateClusterStretchChange(stretchTest.changed,
+			func(key string) interface{} { return values[key] }, getChange)
+		if len(stretchTest.expectedErr) == 0 {
+			if err != nil {
+				t.Errorf("%s: unexpected error %v", stretchTest.name, err)
+			}
+		} else if err == nil || !strings.Contains(err.Error(), stretchTest.expectedErr) {
+			t.Errorf("%s: expected error containing %q, got %v", stretchTest.name, stretchTest.expectedErr, err)
+		}
+	}
+}
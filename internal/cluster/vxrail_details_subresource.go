@@ -0,0 +1,227 @@
+/*
+ *  Copyright 2023 VMware, Inc.
+ *    SPDX-License-Identifier: MPL-2.0
+ */
+
+package cluster
+
+import (
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/vmware/terraform-provider-vcf/internal/resource_utils"
+	validationutils "github.com/vmware/terraform-provider-vcf/internal/validation"
+	"github.com/vmware/vcf-sdk-go/models"
+)
+
+// VxRailDetailsSchema this helper function extracts the VxRail Manager schema, so that
+// it's made available for both workload domain and cluster creation.
+func VxRailDetailsSchema() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"dns_name": {
+				Type:         schema.TypeString,
+				Required:     true,
+				Description:  "DNS name (FQDN) of the VxRail Manager",
+				ValidateFunc: validation.NoZeroValues,
+			},
+			"ip_address": {
+				Type:         schema.TypeString,
+				Required:     true,
+				Description:  "IPv4 address of the VxRail Manager",
+				ValidateFunc: validationutils.ValidateIPv4AddressSchema,
+			},
+			"admin_credentials": {
+				Type:        schema.TypeList,
+				Required:    true,
+				MaxItems:    1,
+				Description: "Credentials of the VxRail Manager admin user",
+				Elem:        vxRailCredentialsSchema(),
+			},
+			"root_credentials": {
+				Type:        schema.TypeList,
+				Required:    true,
+				MaxItems:    1,
+				Description: "Credentials of the VxRail Manager root user",
+				Elem:        vxRailCredentialsSchema(),
+			},
+			"nic_profile": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  "NIC profile of the VxRail cluster, e.g. TWO_HIGH_SPEED or FOUR_HIGH_SPEED",
+				ValidateFunc: validation.NoZeroValues,
+			},
+			"ssh_thumbprint": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  "SSH thumbprint of the VxRail Manager",
+				ValidateFunc: validation.NoZeroValues,
+			},
+			"ssl_thumbprint": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  "SSL thumbprint of the VxRail Manager",
+				ValidateFunc: validation.NoZeroValues,
+			},
+			"network": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Description: "Networks of the VxRail cluster",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"type": {
+							Type:         schema.TypeString,
+							Required:     true,
+							Description:  "Type of the network, e.g. MANAGEMENT, VSAN or VMOTION",
+							ValidateFunc: validation.NoZeroValues,
+						},
+						"vlan_id": {
+							Type:         schema.TypeInt,
+							Required:     true,
+							Description:  "VLAN ID associated with the network",
+							ValidateFunc: validation.IntBetween(0, 4095),
+						},
+						"subnet": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							Description:  "Subnet associated with the network",
+							ValidateFunc: validationutils.ValidateIPv4AddressSchema,
+						},
+						"mask": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							Description:  "Subnet mask for the subnet of the network",
+							ValidateFunc: validationutils.ValidateIPv4AddressSchema,
+						},
+						"gateway": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							Description:  "Gateway for the network",
+							ValidateFunc: validationutils.ValidateIPv4AddressSchema,
+						},
+						"mtu": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Description:  "MTU of the network",
+							ValidateFunc: validation.IntBetween(1500, 9000),
+						},
+						"ip_pools": {
+							Type:        schema.TypeList,
+							Optional:    true,
+							Description: "List of IP pool ranges to use",
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"start": {
+										Type:         schema.TypeString,
+										Required:     true,
+										Description:  "Start IP address of the IP pool",
+										ValidateFunc: validationutils.ValidateIPv4AddressSchema,
+									},
+									"end": {
+										Type:         schema.TypeString,
+										Required:     true,
+										Description:  "End IP address of the IP pool",
+										ValidateFunc: validationutils.ValidateIPv4AddressSchema,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func vxRailCredentialsSchema() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"username": {
+				Type:         schema.TypeString,
+				Required:     true,
+				Description:  "Username",
+				ValidateFunc: validation.NoZeroValues,
+			},
+			"password": {
+				Type:         schema.TypeString,
+				Required:     true,
+				Sensitive:    true,
+				Description:  "Password",
+				ValidateFunc: validation.NoZeroValues,
+			},
+		},
+	}
+}
+
+// TryConvertToVxRailDetails is a convenience method that converts a map[string]interface{}
+// received from the Terraform SDK to an API struct, used in VCF API calls.
+func TryConvertToVxRailDetails(object map[string]interface{}) (*models.VxRailDetails, error) {
+	if object == nil {
+		return nil, fmt.Errorf("cannot convert to VxRailDetails, object is nil")
+	}
+	dnsName := object["dns_name"].(string)
+	if len(dnsName) == 0 {
+		return nil, fmt.Errorf("cannot convert to VxRailDetails, dns_name is required")
+	}
+	result := &models.VxRailDetails{}
+	result.DNSName = dnsName
+	result.IPAddress = object["ip_address"].(string)
+
+	adminCredentials, err := tryConvertToVxRailCredential(object["admin_credentials"])
+	if err != nil {
+		return nil, fmt.Errorf("cannot convert to VxRailDetails, admin_credentials: %w", err)
+	}
+	result.AdminCredentials = adminCredentials
+	rootCredentials, err := tryConvertToVxRailCredential(object["root_credentials"])
+	if err != nil {
+		return nil, fmt.Errorf("cannot convert to VxRailDetails, root_credentials: %w", err)
+	}
+	result.RootCredentials = rootCredentials
+
+	if nicProfile, ok := object["nic_profile"]; ok && !validationutils.IsEmpty(nicProfile) {
+		result.NicProfile = nicProfile.(string)
+	}
+	if sshThumbprint, ok := object["ssh_thumbprint"]; ok && !validationutils.IsEmpty(sshThumbprint) {
+		result.SSHThumbprint = sshThumbprint.(string)
+	}
+	if sslThumbprint, ok := object["ssl_thumbprint"]; ok && !validationutils.IsEmpty(sslThumbprint) {
+		result.SSLThumbprint = sslThumbprint.(string)
+	}
+
+	if networksRaw, ok := object["network"]; ok {
+		for _, networkRaw := range networksRaw.([]interface{}) {
+			networkMap := networkRaw.(map[string]interface{})
+			network := &models.Network{
+				Type:    networkMap["type"].(string),
+				VlanID:  int32(networkMap["vlan_id"].(int)),
+				Subnet:  networkMap["subnet"].(string),
+				Mask:    networkMap["mask"].(string),
+				Gateway: networkMap["gateway"].(string),
+				Mtu:     int32(networkMap["mtu"].(int)),
+			}
+			for _, ipPoolRaw := range networkMap["ip_pools"].([]interface{}) {
+				ipPoolMap := ipPoolRaw.(map[string]interface{})
+				network.IPPools = append(network.IPPools, &models.IPPool{
+					Start: ipPoolMap["start"].(string),
+					End:   ipPoolMap["end"].(string),
+				})
+			}
+			result.Networks = append(result.Networks, network)
+		}
+	}
+
+	return result, nil
+}
+
+func tryConvertToVxRailCredential(credentialsRaw interface{}) (*models.UnmanagedResourceCredential, error) {
+	credentialsList, ok := credentialsRaw.([]interface{})
+	if !ok || len(credentialsList) == 0 || credentialsList[0] == nil {
+		return nil, fmt.Errorf("credentials are required")
+	}
+	credentials := credentialsList[0].(map[string]interface{})
+	return &models.UnmanagedResourceCredential{
+		CredentialType: resource_utils.ToStringPointer("SSH"),
+		Username:       resource_utils.ToStringPointer(credentials["username"]),
+		Password:       credentials["password"].(string),
+	}, nil
+}
@@ -0,0 +1,102 @@
+/*
+ *  Copyright 2023 VMware, Inc.
+ *    SPDX-License-Identifier: MPL-2.0
+ */
+
+package network
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"golang.org/x/crypto/ssh"
+	"net"
+	"strings"
+	"time"
+)
+
+// thumbprintDiscoveryTimeout the timeout for connecting to an ESXi host to discover its thumbprints.
+const thumbprintDiscoveryTimeout = 30 * time.Second
+
+var errHostKeyReceived = errors.New("host key received")
+
+// GetSshThumbprint returns the SHA-256 fingerprint of the RSA SSH host key of an ESXi host, in the
+// "SHA256:<base64>" format expected by SDDC Manager. The connection is closed after the key exchange.
+func GetSshThumbprint(ctx context.Context, address string) (string, error) {
+	dialer := &net.Dialer{Timeout: thumbprintDiscoveryTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(address, "22"))
+	if err != nil {
+		return "", fmt.Errorf("failed to connect to host %q to discover its SSH thumbprint: %w", address, err)
+	}
+	defer conn.Close()
+
+	var thumbprint string
+	clientConfig := &ssh.ClientConfig{
+		User: "root",
+		// SDDC Manager verifies the RSA host key
+		HostKeyAlgorithms: []string{ssh.KeyAlgoRSASHA512, ssh.KeyAlgoRSASHA256, ssh.KeyAlgoRSA},
+		HostKeyCallback: func(_ string, _ net.Addr, key ssh.PublicKey) error {
+			thumbprint = ssh.FingerprintSHA256(key)
+			// the authentication is not needed
+			return errHostKeyReceived
+		},
+		Timeout: thumbprintDiscoveryTimeout,
+	}
+	_, _, _, err = ssh.NewClientConn(conn, address, clientConfig)
+	if len(thumbprint) == 0 {
+		return "", fmt.Errorf("failed to discover the SSH thumbprint of host %q: %w", address, err)
+	}
+	return thumbprint, nil
+}
+
+// GetSslThumbprint returns the SHA-256 thumbprint of the TLS certificate of an ESXi host, as
+// colon-separated upper case hex digits.
+func GetSslThumbprint(ctx context.Context, address string) (string, error) {
+	dialer := &tls.Dialer{
+		NetDialer: &net.Dialer{Timeout: thumbprintDiscoveryTimeout},
+		// the certificates of ESXi hosts are usually self-signed, the certificate is pinned by its thumbprint instead
+		Config: &tls.Config{InsecureSkipVerify: true}, // #nosec G402
+	}
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(address, "443"))
+	if err != nil {
+		return "", fmt.Errorf("failed to connect to host %q to discover its SSL thumbprint: %w", address, err)
+	}
+	defer conn.Close()
+
+	peerCertificates := conn.(*tls.Conn).ConnectionState().PeerCertificates
+	if len(peerCertificates) == 0 {
+		return "", fmt.Errorf("failed to discover the SSL thumbprint of host %q, no certificate received", address)
+	}
+	digest := sha256.Sum256(peerCertificates[0].Raw)
+	hexBytes := make([]string, 0, len(digest))
+	for _, digestByte := range digest {
+		hexBytes = append(hexBytes, fmt.Sprintf("%02X", digestByte))
+	}
+	return strings.Join(hexBytes, ":"), nil
+}
+
+// IsHostReachable returns whether a connection to the HTTPS port of an ESXi host can be established.
+func IsHostReachable(ctx context.Context, address string) bool {
+	dialer := &net.Dialer{Timeout: thumbprintDiscoveryTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(address, "443"))
+	if err != nil {
+		return false
+	}
+	_ = conn.Close()
+	return true
+}
+
+// VerifyPinnedThumbprint returns an error if the thumbprint discovered on a host doesn't match the
+// thumbprint pinned for it. SSL thumbprints are compared case-insensitively.
+func VerifyPinnedThumbprint(thumbprintType, address, pinnedThumbprint, discoveredThumbprint string) error {
+	if pinnedThumbprint == discoveredThumbprint ||
+		(thumbprintType == "SSL" && strings.EqualFold(pinnedThumbprint, discoveredThumbprint)) {
+		return nil
+	}
+	return fmt.Errorf("SECURITY: the %s thumbprint of host %q is %q, which doesn't match the pinned thumbprint "+
+		"%q. The host may have been reinstalled, or the connection to it may be intercepted. Verify the identity "+
+		"of the host and, if the change is expected, set the new thumbprint in the configuration",
+		thumbprintType, address, discoveredThumbprint, pinnedThumbprint)
+}
@@ -0,0 +1,55 @@
+/*
+ *  Copyright 2023 VMware, Inc.
+ *    SPDX-License-Identifier: MPL-2.0
+ */
+
+package network
+
+import (
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/vmware/vcf-sdk-go/models"
+)
+
+// NetworkProfileSchema this helper function extracts the cluster network profile schema, which
+// defines the host networking once for all hosts of a cluster.
+func NetworkProfileSchema() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"vmnic": {
+				Type:     schema.TypeList,
+				Required: true,
+				MinItems: 1,
+				Description: "vmnic configuration, i.e. the mapping of the vmnics to the VDSes, the uplinks and " +
+					"the NSX host switch, applied to every host in the cluster",
+				Elem: VMNicSchema(),
+			},
+		},
+	}
+}
+
+// TryConvertToHostNetworkSpec is a convenience method that converts a network profile
+// map[string]interface{} received from the Terraform SDK to an API struct, used in VCF API calls.
+// vdsNames are the names of the VDSes in the cluster, if not empty the vmnics can only be associated with them.
+func TryConvertToHostNetworkSpec(object map[string]interface{}, vdsNames map[string]bool) (*models.HostNetworkSpec, error) {
+	if object == nil {
+		return nil, fmt.Errorf("cannot convert to HostNetworkSpec, object is nil")
+	}
+	vmNicsList := object["vmnic"].([]interface{})
+	if len(vmNicsList) == 0 {
+		return nil, fmt.Errorf("cannot convert to HostNetworkSpec, vmnic list is empty")
+	}
+	result := &models.HostNetworkSpec{}
+	for _, vmNicListEntry := range vmNicsList {
+		vmNic, err := TryConvertToVmNic(vmNicListEntry.(map[string]interface{}))
+		if err != nil {
+			return nil, err
+		}
+		if len(vmNic.VdsName) > 0 && len(vdsNames) > 0 && !vdsNames[vmNic.VdsName] {
+			return nil, fmt.Errorf("cannot convert to HostNetworkSpec, vmnic %q is associated with VDS %q, "+
+				"which is not part of the cluster", vmNic.ID, vmNic.VdsName)
+		}
+		result.VMNics = append(result.VMNics, vmNic)
+	}
+	return result, nil
+}
@@ -0,0 +1,67 @@
+/*
+ *  Copyright 2023 VMware, Inc.
+ *    SPDX-License-Identifier: MPL-2.0
+ */
+
+package network
+
+import (
+	"github.com/vmware/vcf-sdk-go/models"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTryConvertToHostNetworkSpec(t *testing.T) {
+	vdsNames := map[string]bool{"sfo-w01-cl01-vds01": true, "sfo-w01-cl01-vds02": true}
+
+	var profileTests = []struct {
+		name           string
+		networkProfile map[string]interface{}
+		vdsNames       map[string]bool
+		expectedVmNics []*models.VMNic
+		expectedErr    string
+	}{
+		{"vmnic mapping", map[string]interface{}{"vmnic": []interface{}{
+			map[string]interface{}{"id": "vmnic0", "vds_name": "sfo-w01-cl01-vds01", "uplink": "uplink1"},
+			map[string]interface{}{"id": "vmnic1", "vds_name": "sfo-w01-cl01-vds02", "uplink": "uplink2"},
+		}}, vdsNames, []*models.VMNic{
+			{ID: "vmnic0", VdsName: "sfo-w01-cl01-vds01", Uplink: "uplink1"},
+			{ID: "vmnic1", VdsName: "sfo-w01-cl01-vds02", Uplink: "uplink2"},
+		}, ""},
+		{"move to nvds", map[string]interface{}{"vmnic": []interface{}{
+			map[string]interface{}{"id": "vmnic0", "vds_name": "sfo-w01-cl01-vds01", "move_to_nvds": false},
+			map[string]interface{}{"id": "vmnic2", "move_to_nvds": true},
+		}}, vdsNames, []*models.VMNic{
+			{ID: "vmnic0", VdsName: "sfo-w01-cl01-vds01"},
+			{ID: "vmnic2", MoveToNvds: true},
+		}, ""},
+		{"any vds without cluster vdses", map[string]interface{}{"vmnic": []interface{}{
+			map[string]interface{}{"id": "vmnic0", "vds_name": "other-vds"},
+		}}, nil, []*models.VMNic{{ID: "vmnic0", VdsName: "other-vds"}}, ""},
+		{"vds not in cluster", map[string]interface{}{"vmnic": []interface{}{
+			map[string]interface{}{"id": "vmnic0", "vds_name": "other-vds"},
+		}}, vdsNames, nil, "vmnic \"vmnic0\" is associated with VDS \"other-vds\""},
+		{"empty vmnic list", map[string]interface{}{"vmnic": []interface{}{}}, vdsNames, nil, "vmnic list is empty"},
+		{"vmnic without id", map[string]interface{}{"vmnic": []interface{}{
+			map[string]interface{}{"id": "", "vds_name": "sfo-w01-cl01-vds01"},
+		}}, vdsNames, nil, "id is required"},
+		{"nil profile", nil, vdsNames, nil, "object is nil"},
+	}
+	for _, profileTest := range profileTests {
+		hostNetworkSpec, err := TryConvertToHostNetworkSpec(profileTest.networkProfile, profileTest.vdsNames)
+		if len(profileTest.expectedErr) > 0 {
+			if err == nil || !strings.Contains(err.Error(), profileTest.expectedErr) {
+				t.Errorf("%s: expected error containing %q, got %v", profileTest.name, profileTest.expectedErr, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", profileTest.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(hostNetworkSpec.VMNics, profileTest.expectedVmNics) {
+			t.Errorf("%s: expected vmnics %+v, got %+v", profileTest.name, profileTest.expectedVmNics, hostNetworkSpec.VMNics)
+		}
+	}
+}
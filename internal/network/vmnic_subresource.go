@@ -36,6 +36,11 @@ func VMNicSchema() *schema.Resource {
 				Description:  "Name of the VDS to associate with the ESXi host",
 				ValidateFunc: validation.NoZeroValues,
 			},
+			"move_to_nvds": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Description: "Whether the vmnic is moved to the NSX host switch (N-VDS)",
+			},
 		},
 	}
 }
@@ -56,5 +61,8 @@ func TryConvertToVmNic(object map[string]interface{}) (*models.VMNic, error) {
 	if vdsName, ok := object["vds_name"]; ok && !validationutils.IsEmpty(vdsName) {
 		result.VdsName = vdsName.(string)
 	}
+	if moveToNvds, ok := object["move_to_nvds"]; ok && moveToNvds != nil {
+		result.MoveToNvds = moveToNvds.(bool)
+	}
 	return result, nil
 }
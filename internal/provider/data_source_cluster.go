@@ -7,11 +7,14 @@ package provider
 
 import (
 	"context"
+	"fmt"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	"github.com/vmware/terraform-provider-vcf/internal/cluster"
 	"github.com/vmware/terraform-provider-vcf/internal/network"
+	"github.com/vmware/vcf-sdk-go/client"
+	"github.com/vmware/vcf-sdk-go/models"
 	"time"
 )
 
@@ -24,19 +27,27 @@ func DataSourceCluster() *schema.Resource {
 		Schema: map[string]*schema.Schema{
 			"cluster_id": {
 				Type:         schema.TypeString,
-				Required:     true,
+				Optional:     true,
+				Computed:     true,
+				ExactlyOneOf: []string{"cluster_id", "name"},
 				ValidateFunc: validation.NoZeroValues,
-				Description:  "The ID of the Cluster to be used as data source",
+				Description:  "The ID of the Cluster to be used as data source. Either this or \"name\" is required",
 			},
 			"name": {
-				Type:        schema.TypeString,
-				Computed:    true,
-				Description: "Name of the domain",
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ExactlyOneOf: []string{"cluster_id", "name"},
+				ValidateFunc: validation.NoZeroValues,
+				Description:  "Name of the cluster to be used as data source. Either this or \"cluster_id\" is required",
 			},
 			"domain_id": {
-				Type:        schema.TypeString,
-				Computed:    true,
-				Description: "The ID of a workload domain that the cluster belongs to",
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ValidateFunc: validation.NoZeroValues,
+				Description: "The ID of a workload domain that the cluster belongs to. If set together with " +
+					"\"name\", the cluster is looked up only in this domain",
 			},
 			"host": {
 				Type:        schema.TypeList,
@@ -78,9 +89,51 @@ func dataSourceClusterRead(ctx context.Context, data *schema.ResourceData, meta
 	vcfClient := meta.(*SddcManagerClient)
 	apiClient := vcfClient.ApiClient
 	clusterId := data.Get("cluster_id").(string)
+	if len(clusterId) == 0 {
+		clusterObj, err := getClusterByName(ctx, data.Get("name").(string), data.Get("domain_id").(string), apiClient)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		clusterId = clusterObj.ID
+	}
 	_, err := cluster.ImportCluster(ctx, data, apiClient, clusterId)
 	if err != nil {
 		return diag.FromErr(err)
 	}
+	_ = data.Set("cluster_id", clusterId)
 	return nil
 }
+
+// getClusterByName returns the cluster with the provided name. As cluster names are unique only
+// within a domain, the domain ID is required if clusters in several domains have the same name.
+func getClusterByName(ctx context.Context, name, domainId string, apiClient *client.VcfClient) (*models.Cluster, error) {
+	allClusters, err := getClusters(ctx, apiClient)
+	if err != nil {
+		return nil, err
+	}
+	clusterDomainIds, err := getClusterDomainIds(ctx, apiClient)
+	if err != nil {
+		return nil, err
+	}
+
+	var result *models.Cluster
+	for _, clusterObj := range allClusters {
+		if clusterObj == nil || clusterObj.Name != name {
+			continue
+		}
+		if len(domainId) > 0 && clusterDomainIds[clusterObj.ID] != domainId {
+			continue
+		}
+		if result != nil {
+			return nil, fmt.Errorf("more than one cluster with name %q found, set \"domain_id\" to select one", name)
+		}
+		result = clusterObj
+	}
+	if result == nil {
+		if len(domainId) > 0 {
+			return nil, fmt.Errorf("cluster with name %q not found in domain %q", name, domainId)
+		}
+		return nil, fmt.Errorf("cluster with name %q not found", name)
+	}
+	return result, nil
+}
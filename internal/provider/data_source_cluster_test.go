@@ -37,6 +37,10 @@ func TestAccDataSourceVcfCluster(t *testing.T) {
 					resource.TestCheckResourceAttrSet("data.vcf_cluster.cluster1", "host.2.id"),
 					resource.TestCheckResourceAttrSet("data.vcf_cluster.cluster1", "host.2.host_name"),
 					resource.TestCheckResourceAttrSet("data.vcf_cluster.cluster1", "host.2.ip_address"),
+					resource.TestCheckResourceAttrPair("data.vcf_cluster.by_name", "cluster_id",
+						"data.vcf_cluster.cluster1", "cluster_id"),
+					resource.TestCheckResourceAttrPair("data.vcf_cluster.by_name", "domain_id",
+						"data.vcf_cluster.cluster1", "domain_id"),
 				),
 			},
 		},
@@ -47,5 +51,10 @@ func testAccVcfClusterDataSourceConfig(domainId string) string {
 	return fmt.Sprintf(`
 	data "vcf_cluster" "cluster1" {
 		cluster_id = %q
+	}
+
+	data "vcf_cluster" "by_name" {
+		name      = data.vcf_cluster.cluster1.name
+		domain_id = data.vcf_cluster.cluster1.domain_id
 	}`, domainId)
 }
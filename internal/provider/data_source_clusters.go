@@ -0,0 +1,177 @@
+/*
+ *  Copyright 2023 VMware, Inc.
+ *    SPDX-License-Identifier: MPL-2.0
+ */
+
+package provider
+
+import (
+	"context"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/vmware/terraform-provider-vcf/internal/constants"
+	"github.com/vmware/vcf-sdk-go/client"
+	"github.com/vmware/vcf-sdk-go/client/clusters"
+	"github.com/vmware/vcf-sdk-go/models"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func DataSourceClusters() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceClustersRead,
+		Timeouts: &schema.ResourceTimeout{
+			Read: schema.DefaultTimeout(20 * time.Minute),
+		},
+		Schema: map[string]*schema.Schema{
+			"domain_id": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  "Return only the clusters in the domain with the given ID",
+				ValidateFunc: validation.NoZeroValues,
+			},
+			"clusters": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "List of the clusters matching the filters, sorted by name",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "ID of the cluster",
+						},
+						"name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Name of the cluster",
+						},
+						"domain_id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "ID of the domain that the cluster belongs to",
+						},
+						"host_ids": {
+							Type:        schema.TypeList,
+							Computed:    true,
+							Description: "IDs of the ESXi hosts in the cluster, sorted",
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+						"primary_datastore_name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Name of the primary datastore",
+						},
+						"primary_datastore_type": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Storage type of the primary datastore",
+						},
+						"is_default": {
+							Type:        schema.TypeBool,
+							Computed:    true,
+							Description: "Status of the cluster if default or not",
+						},
+						"is_stretched": {
+							Type:        schema.TypeBool,
+							Computed:    true,
+							Description: "Status of the cluster if stretched or not",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceClustersRead(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	vcfClient := meta.(*SddcManagerClient)
+	apiClient := vcfClient.ApiClient
+
+	clustersList, err := getClusters(ctx, apiClient)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	var allClusters []*models.Cluster
+	for _, clusterObj := range clustersList {
+		if clusterObj != nil {
+			allClusters = append(allClusters, clusterObj)
+		}
+	}
+	clusterDomainIds, err := getClusterDomainIds(ctx, apiClient)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	// Sort by name, to have a deterministic order in every run of the clusters datasource read
+	sort.SliceStable(allClusters, func(i, j int) bool {
+		return allClusters[i].Name < allClusters[j].Name
+	})
+
+	domainId := data.Get("domain_id").(string)
+	flattenedClusters := *new([]map[string]interface{})
+	var clusterIds []string
+	for _, clusterObj := range allClusters {
+		if len(domainId) > 0 && clusterDomainIds[clusterObj.ID] != domainId {
+			continue
+		}
+		var hostIds []string
+		for _, hostRef := range clusterObj.Hosts {
+			if hostRef != nil {
+				hostIds = append(hostIds, hostRef.ID)
+			}
+		}
+		sort.Strings(hostIds)
+		flattenedClusters = append(flattenedClusters, map[string]interface{}{
+			"id":                     clusterObj.ID,
+			"name":                   clusterObj.Name,
+			"domain_id":              clusterDomainIds[clusterObj.ID],
+			"host_ids":               hostIds,
+			"primary_datastore_name": clusterObj.PrimaryDatastoreName,
+			"primary_datastore_type": clusterObj.PrimaryDatastoreType,
+			"is_default":             clusterObj.IsDefault,
+			"is_stretched":           clusterObj.IsStretched,
+		})
+		clusterIds = append(clusterIds, clusterObj.ID)
+	}
+
+	data.SetId(strconv.Itoa(schema.HashString(strings.Join(clusterIds, ","))))
+	_ = data.Set("clusters", flattenedClusters)
+
+	return nil
+}
+
+func getClusters(ctx context.Context, apiClient *client.VcfClient) ([]*models.Cluster, error) {
+	getClustersParams := clusters.NewGetClustersParamsWithContext(ctx).
+		WithTimeout(constants.DefaultVcfApiCallTimeout)
+	clustersResult, err := apiClient.Clusters.GetClusters(getClustersParams)
+	if err != nil {
+		return nil, err
+	}
+	return clustersResult.Payload.Elements, nil
+}
+
+// getClusterDomainIds returns the IDs of the domains that the clusters belong to, indexed by cluster ID,
+// because the cluster API doesn't provide the parent domain ID.
+func getClusterDomainIds(ctx context.Context, apiClient *client.VcfClient) (map[string]string, error) {
+	allDomains, err := getDomains(ctx, "", apiClient)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]string)
+	for _, domain := range allDomains {
+		if domain == nil {
+			continue
+		}
+		for _, clusterRef := range domain.Clusters {
+			if clusterRef != nil && clusterRef.ID != nil {
+				result[*clusterRef.ID] = domain.ID
+			}
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,51 @@
+/*
+ *  Copyright 2023 VMware, Inc.
+ *    SPDX-License-Identifier: MPL-2.0
+ */
+
+package provider
+
+import (
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/vmware/terraform-provider-vcf/internal/constants"
+	"os"
+	"testing"
+)
+
+func TestAccDataSourceVcfClusters(t *testing.T) {
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccVcfClustersDataSourceConfig(
+					os.Getenv(constants.VcfTestClusterDataSourceId)),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrSet("data.vcf_clusters.all", "clusters.0.id"),
+					resource.TestCheckResourceAttrSet("data.vcf_clusters.all", "clusters.0.name"),
+					resource.TestCheckResourceAttrSet("data.vcf_clusters.all", "clusters.0.domain_id"),
+					resource.TestCheckResourceAttrSet("data.vcf_clusters.all", "clusters.0.host_ids.0"),
+					resource.TestCheckResourceAttrSet("data.vcf_clusters.all", "clusters.0.primary_datastore_name"),
+					resource.TestCheckResourceAttrSet("data.vcf_clusters.all", "clusters.0.is_stretched"),
+					resource.TestCheckResourceAttrPair("data.vcf_clusters.in_domain", "domain_id",
+						"data.vcf_clusters.in_domain", "clusters.0.domain_id"),
+				),
+			},
+		},
+	})
+}
+
+func testAccVcfClustersDataSourceConfig(clusterId string) string {
+	return fmt.Sprintf(`
+	data "vcf_cluster" "cluster1" {
+		cluster_id = %q
+	}
+
+	data "vcf_clusters" "all" {
+	}
+
+	data "vcf_clusters" "in_domain" {
+		domain_id = data.vcf_cluster.cluster1.domain_id
+	}`, clusterId)
+}
@@ -31,14 +31,19 @@ func DataSourceDomain() *schema.Resource {
 		Schema: map[string]*schema.Schema{
 			"domain_id": {
 				Type:         schema.TypeString,
-				Required:     true,
+				Optional:     true,
+				Computed:     true,
+				ExactlyOneOf: []string{"domain_id", "name"},
 				ValidateFunc: validation.NoZeroValues,
-				Description:  "The ID of the Domain to be used as data source",
+				Description:  "The ID of the Domain to be used as data source. Either this or \"name\" is required",
 			},
 			"name": {
-				Type:        schema.TypeString,
-				Computed:    true,
-				Description: "Name of the domain",
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ExactlyOneOf: []string{"domain_id", "name"},
+				ValidateFunc: validation.NoZeroValues,
+				Description:  "Name of the domain to be used as data source. Either this or \"domain_id\" is required",
 			},
 			"cluster": {
 				Type:        schema.TypeList,
@@ -95,16 +100,26 @@ func dataSourceDomainRead(ctx context.Context, data *schema.ResourceData, meta i
 	vcfClient := meta.(*SddcManagerClient)
 	apiClient := vcfClient.ApiClient
 
-	getDomainParams := domains.NewGetDomainParamsWithContext(ctx).
-		WithTimeout(constants.DefaultVcfApiCallTimeout)
-	getDomainParams.ID = data.Get("domain_id").(string)
-	domainResult, err := apiClient.Domains.GetDomain(getDomainParams)
-	if err != nil {
-		return diag.FromErr(err)
+	var domain *models.Domain
+	if domainId, ok := data.GetOk("domain_id"); ok {
+		getDomainParams := domains.NewGetDomainParamsWithContext(ctx).
+			WithTimeout(constants.DefaultVcfApiCallTimeout)
+		getDomainParams.ID = domainId.(string)
+		domainResult, err := apiClient.Domains.GetDomain(getDomainParams)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		domain = domainResult.Payload
+	} else {
+		var err error
+		domain, err = getDomainByName(ctx, data.Get("name").(string), apiClient)
+		if err != nil {
+			return diag.FromErr(err)
+		}
 	}
-	domain := domainResult.Payload
 
 	data.SetId(domain.ID)
+	_ = data.Set("domain_id", domain.ID)
 	_ = data.Set("name", domain.Name)
 	_ = data.Set("status", domain.Status)
 	_ = data.Set("type", domain.Type)
@@ -117,7 +132,7 @@ func dataSourceDomainRead(ctx context.Context, data *schema.ResourceData, meta i
 	_ = data.Set("vcenter_id", domain.VCENTERS[0].ID)
 	_ = data.Set("vcenter_fqdn", domain.VCENTERS[0].Fqdn)
 
-	err = setClustersDataToDomainDataSource(domain.Clusters, ctx, data, apiClient)
+	err := setClustersDataToDomainDataSource(domain.Clusters, ctx, data, apiClient)
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -135,6 +150,11 @@ func setClustersDataToDomainDataSource(domainClusterRefs []*models.ClusterRefere
 	// Sort the id slice, to have a deterministic order in every run of the domain datasource read
 	sort.Strings(clusterIds)
 
+	hostsById, err := cluster.GetHostsInDomain(ctx, data.Id(), apiClient)
+	if err != nil {
+		return err
+	}
+
 	flattenedClusters := make([]map[string]interface{}, len(domainClusterRefs))
 	for i, clusterId := range clusterIds {
 		getClusterParams := clusters.GetClusterParams{ID: clusterId}
@@ -144,10 +164,37 @@ func setClustersDataToDomainDataSource(domainClusterRefs []*models.ClusterRefere
 			return err
 		}
 		clusterRef := clusterResult.Payload
-		flattenedClusters[i] = *cluster.FlattenCluster(clusterRef)
+		flattenedClusters[i] = *cluster.FlattenCluster(clusterRef, hostsById)
 
 	}
 	_ = data.Set("cluster", flattenedClusters)
 
 	return nil
 }
+
+// getDomains returns all domains, optionally filtered by type if domainType is not empty.
+func getDomains(ctx context.Context, domainType string, apiClient *client.VcfClient) ([]*models.Domain, error) {
+	getDomainsParams := domains.NewGetDomainsParamsWithContext(ctx).
+		WithTimeout(constants.DefaultVcfApiCallTimeout)
+	if len(domainType) > 0 {
+		getDomainsParams.Type = &domainType
+	}
+	domainsResult, err := apiClient.Domains.GetDomains(getDomainsParams)
+	if err != nil {
+		return nil, err
+	}
+	return domainsResult.Payload.Elements, nil
+}
+
+func getDomainByName(ctx context.Context, name string, apiClient *client.VcfClient) (*models.Domain, error) {
+	allDomains, err := getDomains(ctx, "", apiClient)
+	if err != nil {
+		return nil, err
+	}
+	for _, domain := range allDomains {
+		if domain != nil && domain.Name == name {
+			return domain, nil
+		}
+	}
+	return nil, fmt.Errorf("domain with name %q not found", name)
+}
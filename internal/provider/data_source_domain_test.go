@@ -40,6 +40,16 @@ func TestAccDataSourceVcfDomain(t *testing.T) {
 					resource.TestCheckResourceAttrSet("data.vcf_domain.domain1", "cluster.0.host.3.id"),
 				),
 			},
+			{
+				Config: testAccVcfDomainDataSourceByNameConfig(
+					os.Getenv(constants.VcfTestDomainDataSourceId)),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPair("data.vcf_domain.domain2", "domain_id",
+						"data.vcf_domain.domain1", "domain_id"),
+					resource.TestCheckResourceAttrPair("data.vcf_domain.domain2", "vcenter_fqdn",
+						"data.vcf_domain.domain1", "vcenter_fqdn"),
+				),
+			},
 		},
 	})
 }
@@ -50,3 +60,14 @@ func testAccVcfDomainDataSourceConfig(domainId string) string {
 		domain_id = %q
 	}`, domainId)
 }
+
+func testAccVcfDomainDataSourceByNameConfig(domainId string) string {
+	return fmt.Sprintf(`
+	data "vcf_domain" "domain1" {
+		domain_id = %q
+	}
+
+	data "vcf_domain" "domain2" {
+		name = data.vcf_domain.domain1.name
+	}`, domainId)
+}
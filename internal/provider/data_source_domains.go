@@ -0,0 +1,112 @@
+/*
+ *  Copyright 2023 VMware, Inc.
+ *    SPDX-License-Identifier: MPL-2.0
+ */
+
+package provider
+
+import (
+	"context"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/vmware/vcf-sdk-go/models"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func DataSourceDomains() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceDomainsRead,
+		Timeouts: &schema.ResourceTimeout{
+			Read: schema.DefaultTimeout(20 * time.Minute),
+		},
+		Schema: map[string]*schema.Schema{
+			"type": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  "Return only the domains of the given type. One among: MANAGEMENT, VI",
+				ValidateFunc: validation.StringInSlice([]string{"MANAGEMENT", "VI"}, true),
+			},
+			"status": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  "Return only the domains with the given status, e.g. ACTIVE",
+				ValidateFunc: validation.NoZeroValues,
+			},
+			"domains": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "List of the domains matching the filters, sorted by name",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "ID of the domain",
+						},
+						"name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Name of the domain",
+						},
+						"type": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Type of the domain",
+						},
+						"status": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Status of the domain",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceDomainsRead(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	vcfClient := meta.(*SddcManagerClient)
+	apiClient := vcfClient.ApiClient
+
+	domainType := strings.ToUpper(data.Get("type").(string))
+	domainsList, err := getDomains(ctx, domainType, apiClient)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	var allDomains []*models.Domain
+	for _, domain := range domainsList {
+		if domain != nil {
+			allDomains = append(allDomains, domain)
+		}
+	}
+	// Sort by name, to have a deterministic order in every run of the domains datasource read
+	sort.SliceStable(allDomains, func(i, j int) bool {
+		return allDomains[i].Name < allDomains[j].Name
+	})
+
+	status := data.Get("status").(string)
+	flattenedDomains := *new([]map[string]interface{})
+	var domainIds []string
+	for _, domain := range allDomains {
+		if len(status) > 0 && !strings.EqualFold(domain.Status, status) {
+			continue
+		}
+		flattenedDomains = append(flattenedDomains, map[string]interface{}{
+			"id":     domain.ID,
+			"name":   domain.Name,
+			"type":   domain.Type,
+			"status": domain.Status,
+		})
+		domainIds = append(domainIds, domain.ID)
+	}
+
+	data.SetId(strconv.Itoa(schema.HashString(strings.Join(domainIds, ","))))
+	_ = data.Set("domains", flattenedDomains)
+
+	return nil
+}
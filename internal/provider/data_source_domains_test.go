@@ -0,0 +1,41 @@
+/*
+ *  Copyright 2023 VMware, Inc.
+ *    SPDX-License-Identifier: MPL-2.0
+ */
+
+package provider
+
+import (
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"testing"
+)
+
+func TestAccDataSourceVcfDomains(t *testing.T) {
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccVcfDomainsDataSourceConfig(),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.vcf_domains.management", "domains.#", "1"),
+					resource.TestCheckResourceAttrSet("data.vcf_domains.management", "domains.0.id"),
+					resource.TestCheckResourceAttrSet("data.vcf_domains.management", "domains.0.name"),
+					resource.TestCheckResourceAttr("data.vcf_domains.management", "domains.0.type", "MANAGEMENT"),
+					resource.TestCheckResourceAttrSet("data.vcf_domains.management", "domains.0.status"),
+					resource.TestCheckResourceAttrSet("data.vcf_domains.all", "domains.0.id"),
+				),
+			},
+		},
+	})
+}
+
+func testAccVcfDomainsDataSourceConfig() string {
+	return `
+	data "vcf_domains" "management" {
+		type = "MANAGEMENT"
+	}
+
+	data "vcf_domains" "all" {
+	}`
+}
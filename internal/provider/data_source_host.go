@@ -0,0 +1,228 @@
+/*
+ *  Copyright 2023 VMware, Inc.
+ *    SPDX-License-Identifier: MPL-2.0
+ */
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/vmware/terraform-provider-vcf/internal/cluster"
+	"github.com/vmware/terraform-provider-vcf/internal/constants"
+	"github.com/vmware/vcf-sdk-go/client"
+	"github.com/vmware/vcf-sdk-go/client/hosts"
+	"github.com/vmware/vcf-sdk-go/models"
+	"strings"
+	"time"
+)
+
+func DataSourceHost() *schema.Resource {
+	hostSchema := hostDataSchema()
+	// the ID of the data source is the ID of the host
+	delete(hostSchema, "id")
+	hostSchema["host_id"] = &schema.Schema{
+		Type:         schema.TypeString,
+		Optional:     true,
+		Computed:     true,
+		ExactlyOneOf: []string{"host_id", "fqdn"},
+		ValidateFunc: validation.NoZeroValues,
+		Description:  "The ID of the ESXi host to be used as data source. Either this or \"fqdn\" is required",
+	}
+	hostSchema["fqdn"] = &schema.Schema{
+		Type:         schema.TypeString,
+		Optional:     true,
+		Computed:     true,
+		ExactlyOneOf: []string{"host_id", "fqdn"},
+		ValidateFunc: validation.NoZeroValues,
+		Description:  "Fully qualified domain name of the ESXi host to be used as data source. Either this or \"host_id\" is required",
+	}
+
+	return &schema.Resource{
+		ReadContext: dataSourceHostRead,
+		Timeouts: &schema.ResourceTimeout{
+			Read: schema.DefaultTimeout(20 * time.Minute),
+		},
+		Schema: hostSchema,
+	}
+}
+
+// hostDataSchema the computed attributes of an ESXi host, shared by the vcf_host and vcf_hosts data sources.
+func hostDataSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"id": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "ID of the ESXi host",
+		},
+		"fqdn": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "Fully qualified domain name of the ESXi host",
+		},
+		"status": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "Status of the ESXi host, e.g. ASSIGNED, UNASSIGNED_USEABLE",
+		},
+		"domain_id": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "ID of the domain that the ESXi host is assigned to",
+		},
+		"domain_name": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "Name of the domain that the ESXi host is assigned to",
+		},
+		"cluster_id": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "ID of the cluster that the ESXi host is assigned to",
+		},
+		"network_pool_id": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "ID of the network pool that the ESXi host is associated with",
+		},
+		"network_pool_name": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "Name of the network pool that the ESXi host is associated with",
+		},
+		"storage_type": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "Storage type that the ESXi host is compatible with",
+		},
+		"esxi_version": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "Version of the ESXi software on the host",
+		},
+		"hardware_vendor": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "Hardware vendor of the ESXi host",
+		},
+		"hardware_model": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "Hardware model of the ESXi host",
+		},
+		"ip_address": {
+			Type:        schema.TypeList,
+			Computed:    true,
+			Description: "IP addresses of the ESXi host",
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"ip_address": {
+						Type:        schema.TypeString,
+						Computed:    true,
+						Description: "IP address",
+					},
+					"type": {
+						Type:        schema.TypeString,
+						Computed:    true,
+						Description: "Type of the IP address, e.g. MANAGEMENT, VSAN, VMOTION",
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceHostRead(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	vcfClient := meta.(*SddcManagerClient)
+	apiClient := vcfClient.ApiClient
+
+	var hostObj *models.Host
+	var err error
+	if hostId := data.Get("host_id").(string); len(hostId) > 0 {
+		hostObj, err = getHost(ctx, hostId, apiClient)
+	} else {
+		hostObj, err = getHostByFqdn(ctx, data.Get("fqdn").(string), apiClient)
+	}
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	data.SetId(hostObj.ID)
+	_ = data.Set("host_id", hostObj.ID)
+	for attributeName, attributeValue := range flattenHost(hostObj) {
+		if attributeName == "id" {
+			continue
+		}
+		_ = data.Set(attributeName, attributeValue)
+	}
+	return nil
+}
+
+func getHost(ctx context.Context, hostId string, apiClient *client.VcfClient) (*models.Host, error) {
+	getHostParams := hosts.NewGetHostParamsWithContext(ctx).
+		WithTimeout(constants.DefaultVcfApiCallTimeout)
+	getHostParams.ID = hostId
+	hostResult, err := apiClient.Hosts.GetHost(getHostParams)
+	if err != nil {
+		return nil, err
+	}
+	return hostResult.Payload, nil
+}
+
+// getHostByFqdn returns the ESXi host with the provided FQDN, compared case-insensitively.
+func getHostByFqdn(ctx context.Context, fqdn string, apiClient *client.VcfClient) (*models.Host, error) {
+	allHosts, err := cluster.GetAllHosts(ctx, apiClient)
+	if err != nil {
+		return nil, err
+	}
+	for _, hostObj := range allHosts {
+		if strings.EqualFold(hostObj.Fqdn, fqdn) {
+			return hostObj, nil
+		}
+	}
+	return nil, fmt.Errorf("host with FQDN %q not found", fqdn)
+}
+
+func flattenHost(hostObj *models.Host) map[string]interface{} {
+	result := map[string]interface{}{
+		"id":              hostObj.ID,
+		"fqdn":            hostObj.Fqdn,
+		"status":          hostObj.Status,
+		"storage_type":    hostObj.CompatibleStorageType,
+		"esxi_version":    hostObj.EsxiVersion,
+		"hardware_vendor": hostObj.HardwareVendor,
+		"hardware_model":  hostObj.HardwareModel,
+	}
+	if hostObj.Domain != nil {
+		if hostObj.Domain.ID != nil {
+			result["domain_id"] = *hostObj.Domain.ID
+		}
+		result["domain_name"] = hostObj.Domain.Name
+	}
+	if hostObj.Cluster != nil && hostObj.Cluster.ID != nil {
+		result["cluster_id"] = *hostObj.Cluster.ID
+	}
+	if hostObj.Networkpool != nil {
+		if hostObj.Networkpool.ID != nil {
+			result["network_pool_id"] = *hostObj.Networkpool.ID
+		}
+		result["network_pool_name"] = hostObj.Networkpool.Name
+	}
+
+	flattenedIpAddresses := *new([]map[string]interface{})
+	for _, ipAddress := range hostObj.IPAddresses {
+		if ipAddress == nil {
+			continue
+		}
+		flattenedIpAddresses = append(flattenedIpAddresses, map[string]interface{}{
+			"ip_address": ipAddress.IPAddress,
+			"type":       ipAddress.Type,
+		})
+	}
+	result["ip_address"] = flattenedIpAddresses
+
+	return result
+}
@@ -0,0 +1,51 @@
+/*
+ *  Copyright 2023 VMware, Inc.
+ *    SPDX-License-Identifier: MPL-2.0
+ */
+
+package provider
+
+import (
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"testing"
+)
+
+func TestAccDataSourceVcfHost(t *testing.T) {
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccVcfHostDataSourceConfig(),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.vcf_host.by_fqdn", "status", "ASSIGNED"),
+					resource.TestCheckResourceAttrSet("data.vcf_host.by_fqdn", "host_id"),
+					resource.TestCheckResourceAttrSet("data.vcf_host.by_fqdn", "domain_id"),
+					resource.TestCheckResourceAttrSet("data.vcf_host.by_fqdn", "cluster_id"),
+					resource.TestCheckResourceAttrSet("data.vcf_host.by_fqdn", "network_pool_id"),
+					resource.TestCheckResourceAttrSet("data.vcf_host.by_fqdn", "esxi_version"),
+					resource.TestCheckResourceAttrSet("data.vcf_host.by_fqdn", "ip_address.0.ip_address"),
+					resource.TestCheckResourceAttrPair("data.vcf_host.by_id", "fqdn",
+						"data.vcf_host.by_fqdn", "fqdn"),
+					resource.TestCheckResourceAttrPair("data.vcf_host.by_id", "domain_id",
+						"data.vcf_host.by_fqdn", "domain_id"),
+				),
+			},
+		},
+	})
+}
+
+func testAccVcfHostDataSourceConfig() string {
+	return `
+	data "vcf_hosts" "assigned" {
+		status = "ASSIGNED"
+	}
+
+	data "vcf_host" "by_fqdn" {
+		fqdn = data.vcf_hosts.assigned.hosts.0.fqdn
+	}
+
+	data "vcf_host" "by_id" {
+		host_id = data.vcf_host.by_fqdn.host_id
+	}`
+}
@@ -0,0 +1,111 @@
+/*
+ *  Copyright 2023 VMware, Inc.
+ *    SPDX-License-Identifier: MPL-2.0
+ */
+
+package provider
+
+import (
+	"context"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/vmware/terraform-provider-vcf/internal/constants"
+	"github.com/vmware/terraform-provider-vcf/internal/resource_utils"
+	"github.com/vmware/vcf-sdk-go/client/hosts"
+	"github.com/vmware/vcf-sdk-go/models"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func DataSourceHosts() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceHostsRead,
+		Timeouts: &schema.ResourceTimeout{
+			Read: schema.DefaultTimeout(20 * time.Minute),
+		},
+		Schema: map[string]*schema.Schema{
+			"status": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  "Return only the ESXi hosts with the given status, e.g. ASSIGNED, UNASSIGNED_USEABLE",
+				ValidateFunc: validation.NoZeroValues,
+			},
+			"storage_type": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  "Return only the ESXi hosts compatible with the given storage type. One among: VSAN, VSAN_REMOTE, NFS, VMFS_FC, VVOL",
+				ValidateFunc: validation.StringInSlice([]string{"VSAN", "VSAN_REMOTE", "NFS", "VMFS_FC", "VVOL"}, false),
+			},
+			"network_pool_id": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  "Return only the ESXi hosts associated with the network pool with the given ID",
+				ValidateFunc: validation.NoZeroValues,
+			},
+			"domain_id": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  "Return only the ESXi hosts assigned to the domain with the given ID",
+				ValidateFunc: validation.NoZeroValues,
+			},
+			"hosts": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "List of the ESXi hosts matching the filters, sorted by FQDN",
+				Elem: &schema.Resource{
+					Schema: hostDataSchema(),
+				},
+			},
+		},
+	}
+}
+
+func dataSourceHostsRead(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	vcfClient := meta.(*SddcManagerClient)
+	apiClient := vcfClient.ApiClient
+
+	getHostsParams := hosts.NewGetHostsParamsWithContext(ctx).
+		WithTimeout(constants.DefaultVcfApiCallTimeout)
+	if status, ok := data.GetOk("status"); ok {
+		getHostsParams.Status = resource_utils.ToStringPointer(status)
+	}
+	if storageType, ok := data.GetOk("storage_type"); ok {
+		getHostsParams.StorageType = resource_utils.ToStringPointer(storageType)
+	}
+	if networkPoolId, ok := data.GetOk("network_pool_id"); ok {
+		getHostsParams.NetworkpoolID = resource_utils.ToStringPointer(networkPoolId)
+	}
+	if domainId, ok := data.GetOk("domain_id"); ok {
+		getHostsParams.DomainID = resource_utils.ToStringPointer(domainId)
+	}
+
+	hostsResult, err := apiClient.Hosts.GetHosts(getHostsParams)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	var allHosts []*models.Host
+	for _, hostObj := range hostsResult.Payload.Elements {
+		if hostObj != nil {
+			allHosts = append(allHosts, hostObj)
+		}
+	}
+	// Sort by FQDN, to have a deterministic order in every run of the hosts datasource read
+	sort.SliceStable(allHosts, func(i, j int) bool {
+		return allHosts[i].Fqdn < allHosts[j].Fqdn
+	})
+
+	flattenedHosts := *new([]map[string]interface{})
+	var hostIds []string
+	for _, hostObj := range allHosts {
+		flattenedHosts = append(flattenedHosts, flattenHost(hostObj))
+		hostIds = append(hostIds, hostObj.ID)
+	}
+
+	data.SetId(strconv.Itoa(schema.HashString(strings.Join(hostIds, ","))))
+	_ = data.Set("hosts", flattenedHosts)
+
+	return nil
+}
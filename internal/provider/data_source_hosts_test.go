@@ -0,0 +1,52 @@
+/*
+ *  Copyright 2023 VMware, Inc.
+ *    SPDX-License-Identifier: MPL-2.0
+ */
+
+package provider
+
+import (
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"testing"
+)
+
+func TestAccDataSourceVcfHosts(t *testing.T) {
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccVcfHostsDataSourceConfig(),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrSet("data.vcf_hosts.all", "hosts.0.id"),
+					resource.TestCheckResourceAttrSet("data.vcf_hosts.all", "hosts.0.fqdn"),
+					resource.TestCheckResourceAttrSet("data.vcf_hosts.all", "hosts.0.status"),
+					resource.TestCheckResourceAttr("data.vcf_hosts.assigned", "hosts.0.status", "ASSIGNED"),
+					resource.TestCheckResourceAttrSet("data.vcf_hosts.assigned", "hosts.0.domain_id"),
+					resource.TestCheckResourceAttrPair("data.vcf_hosts.in_domain", "domain_id",
+						"data.vcf_hosts.in_domain", "hosts.0.domain_id"),
+					resource.TestCheckResourceAttrPair("data.vcf_hosts.in_network_pool", "network_pool_id",
+						"data.vcf_hosts.in_network_pool", "hosts.0.network_pool_id"),
+				),
+			},
+		},
+	})
+}
+
+func testAccVcfHostsDataSourceConfig() string {
+	return `
+	data "vcf_hosts" "all" {
+	}
+
+	data "vcf_hosts" "assigned" {
+		status = "ASSIGNED"
+	}
+
+	data "vcf_hosts" "in_domain" {
+		domain_id = data.vcf_hosts.assigned.hosts.0.domain_id
+	}
+
+	data "vcf_hosts" "in_network_pool" {
+		network_pool_id = data.vcf_hosts.assigned.hosts.0.network_pool_id
+	}`
+}
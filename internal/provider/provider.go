@@ -41,17 +41,23 @@ func Provider() *schema.Provider {
 		},
 
 		DataSourcesMap: map[string]*schema.Resource{
-			"vcf_domain":  DataSourceDomain(),
-			"vcf_cluster": DataSourceCluster(),
+			"vcf_domain":   DataSourceDomain(),
+			"vcf_domains":  DataSourceDomains(),
+			"vcf_cluster":  DataSourceCluster(),
+			"vcf_clusters": DataSourceClusters(),
+			"vcf_host":     DataSourceHost(),
+			"vcf_hosts":    DataSourceHosts(),
 		},
 
 		ResourcesMap: map[string]*schema.Resource{
-			"vcf_user":         ResourceUser(),
-			"vcf_network_pool": ResourceNetworkPool(),
-			"vcf_ceip":         ResourceCeip(),
-			"vcf_host":         ResourceHost(),
-			"vcf_domain":       ResourceDomain(),
-			"vcf_cluster":      ResourceCluster(),
+			"vcf_user":              ResourceUser(),
+			"vcf_network_pool":      ResourceNetworkPool(),
+			"vcf_ceip":              ResourceCeip(),
+			"vcf_host":              ResourceHost(),
+			"vcf_domain":            ResourceDomain(),
+			"vcf_management_domain": ResourceManagementDomain(),
+			"vcf_cluster":           ResourceCluster(),
+			"vcf_hosts_commission":  ResourceHostsCommission(),
 		},
 
 		ConfigureContextFunc: providerConfigure,
@@ -4,7 +4,12 @@
 package provider
 
 import (
+	"context"
+	"encoding/json"
+	"github.com/hashicorp/go-cty/cty"
+	ctyjson "github.com/hashicorp/go-cty/cty/json"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 	"github.com/vmware/terraform-provider-vcf/internal/constants"
 	"os"
 	"testing"
@@ -104,3 +109,40 @@ func testAccPreCheck(t *testing.T) {
 		t.Fatal(constants.VcfTestClusterDataSourceId + " must be set for acceptance tests")
 	}
 }
+
+// planResourceChange plans the change of an existing resource from the old to the new configuration,
+// with the raw state and configuration set as in a plan of Terraform.
+func planResourceChange(t *testing.T, resource *schema.Resource, id string, oldConfig, newConfig map[string]interface{}) error {
+	toConfigValue := func(rawConfig map[string]interface{}) cty.Value {
+		configJson, err := json.Marshal(rawConfig)
+		if err != nil {
+			t.Fatal(err)
+		}
+		configValue, err := ctyjson.Unmarshal(configJson, resource.CoreConfigSchema().ImpliedType())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return configValue
+	}
+
+	creationDiff, err := resource.Diff(context.Background(), nil,
+		terraform.NewResourceConfigShimmed(toConfigValue(oldConfig), resource.CoreConfigSchema()), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	state := (&terraform.InstanceState{ID: id}).MergeDiff(creationDiff)
+	for attributeName, attributeValue := range state.Attributes {
+		// the computed attributes are not needed
+		if attributeValue == "74D93920-ED26-11E3-AC10-0800200C9A66" {
+			delete(state.Attributes, attributeName)
+		}
+	}
+	state.RawState, err = state.AttrsAsObjectValue(resource.CoreConfigSchema().ImpliedType())
+	if err != nil {
+		t.Fatal(err)
+	}
+	state.RawConfig = toConfigValue(newConfig)
+	_, err = resource.Diff(context.Background(), state,
+		terraform.NewResourceConfigShimmed(state.RawConfig, resource.CoreConfigSchema()), nil)
+	return err
+}
@@ -5,6 +5,8 @@ package provider
 
 import (
 	"context"
+	"fmt"
+	"github.com/hashicorp/go-cty/cty"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
@@ -14,6 +16,7 @@ import (
 	"github.com/vmware/terraform-provider-vcf/internal/network"
 	"github.com/vmware/terraform-provider-vcf/internal/resource_utils"
 	validationUtils "github.com/vmware/terraform-provider-vcf/internal/validation"
+	"github.com/vmware/terraform-provider-vcf/internal/vcenter"
 	"github.com/vmware/vcf-sdk-go/client/clusters"
 	"github.com/vmware/vcf-sdk-go/models"
 	"log"
@@ -22,12 +25,62 @@ import (
 )
 
 func ResourceCluster() *schema.Resource {
-	clusterResourceSchema := clusterSubresourceSchema().Schema
-	clusterResourceSchema["domain_id"] = &schema.Schema{
-		Type:         schema.TypeString,
-		Required:     true,
-		Description:  "The ID of a workload domain that the cluster belongs to",
-		ValidateFunc: validation.NoZeroValues,
+	clusterResourceSchema := clusterResourceSchemaV0()
+	// the hosts are matched by FQDN or ID, so that only the changes in the membership of the cluster produce a diff
+	clusterResourceSchema["host"] = &schema.Schema{
+		Type:     schema.TypeSet,
+		Optional: true,
+		Computed: true,
+		Description: "List of ESXi host information from the free pool to consume in the cluster. " +
+			"The minimum of 3 hosts is required for vSAN based clusters. For external storage, 2 host clusters " +
+			"are also supported. Computed if host_selector is used",
+		MinItems:     2,
+		Set:          resource_utils.HashByKey("fqdn", "id"),
+		Elem:         cluster.HostSpecSchema(),
+		ExactlyOneOf: []string{"host", "host_selector"},
+	}
+	clusterResourceSchema["host_selector"] = &schema.Schema{
+		Type:     schema.TypeList,
+		Optional: true,
+		MaxItems: 1,
+		Description: "Selects the hosts of the cluster automatically from the free pool. " +
+			"The selected hosts are kept in the state and don't change across plans",
+		Elem:         cluster.HostSelectorSchema(),
+		ExactlyOneOf: []string{"host", "host_selector"},
+	}
+	// SDDC Manager applies the EVC mode and vSphere HA only on creation, they are read and changed in vCenter Server
+	clusterResourceSchema["evc_mode"].Computed = true
+	clusterResourceSchema["evc_mode"].Description = "EVC mode of the cluster. Computed from the cluster in vCenter " +
+		"Server if not set, changes are applied in vCenter Server. Lowering the EVC mode requires the virtual " +
+		"machines that use the higher feature set to be powered off. One among: INTEL_MEROM, " +
+		"INTEL_PENRYN, INTEL_NEALEM, INTEL_WESTMERE, INTEL_SANDYBRIDGE, " +
+		"INTEL_IVYBRIDGE, INTEL_HASWELL, INTEL_BROADWELL, INTEL_SKYLAKE, " +
+		"INTEL_CASCADELAKE, AMD_REV_E, AMD_REV_F, AMD_GREYHOUND_NO3DNOW, " +
+		"AMD_GREYHOUND, AMD_BULLDOZER, AMD_PILEDRIVER, AMD_STREAMROLLER, AMD_ZEN"
+	clusterResourceSchema["high_availability_enabled"].Computed = true
+	clusterResourceSchema["high_availability_enabled"].Description = "vSphere High Availability settings for " +
+		"the cluster. Computed from the cluster in vCenter Server if not set, changes are applied in vCenter Server"
+	clusterResourceSchema["force"] = &schema.Schema{
+		Type:     schema.TypeBool,
+		Optional: true,
+		Default:  false,
+		Description: "Removes hosts from the cluster even if they are dead or disconnected, bypassing the " +
+			"validations of SDDC Manager and the minimum size of the cluster. When the cluster is destroyed, " +
+			"the hosts that can't be connected to on port 443 are removed this way before the cluster is " +
+			"deleted. Forced removal may result in permanent data loss. Only supported by vcf_cluster, the " +
+			"hosts of the clusters in vcf_domain are always removed with the validations",
+		ValidateDiagFunc: func(value interface{}, path cty.Path) diag.Diagnostics {
+			if force, _ := value.(bool); !force {
+				return nil
+			}
+			return diag.Diagnostics{{
+				Severity: diag.Warning,
+				Summary:  "Forced removal of hosts is enabled",
+				Detail: "Hosts removed from the cluster bypass the validations of SDDC Manager. " +
+					"Forced removal may result in permanent data loss, review the recovery plan before applying",
+				AttributePath: path,
+			}}
+		},
 	}
 
 	return &schema.Resource{
@@ -40,10 +93,44 @@ func ResourceCluster() *schema.Resource {
 				vcfClient := meta.(*SddcManagerClient)
 				apiClient := vcfClient.ApiClient
 				clusterId := data.Id()
+				_ = data.Set("deletion_protection", true)
 				return cluster.ImportCluster(ctx, data, apiClient, clusterId)
 			},
 		},
-		Schema: clusterResourceSchema,
+		CustomizeDiff: func(ctx context.Context, diff *schema.ResourceDiff, meta interface{}) error {
+			err := validateCreationOnlyClusterAttributes(diff)
+			if err != nil {
+				return err
+			}
+			err = validateDatastoreMountChanges(diff)
+			if err != nil {
+				return err
+			}
+			// the number of hosts chosen by the host selector is known only after the apply. The configured
+			// passwords differ from their hashes in the state, so only the keys in the diff tell if the stretch changes
+			if diff.NewValueKnown("host") {
+				err = cluster.ValidateClusterStretchChange(len(diff.GetChangedKeysPrefix("stretch")) > 0,
+					diff.Get, diff.GetChange)
+				if err != nil {
+					return err
+				}
+			}
+			// the hosts chosen by the host selector are only known after the apply
+			if selectorList, _ := diff.Get("host_selector").([]interface{}); len(selectorList) > 0 &&
+				diff.HasChange("host_selector") {
+				return diff.SetNewComputed("host")
+			}
+			return nil
+		},
+		Schema:        clusterResourceSchema,
+		SchemaVersion: 1,
+		StateUpgraders: []schema.StateUpgrader{
+			{
+				Type:    (&schema.Resource{Schema: clusterResourceSchemaV0()}).CoreConfigSchema().ImpliedType(),
+				Upgrade: resourceClusterStateUpgradeV0,
+				Version: 0,
+			},
+		},
 		Timeouts: &schema.ResourceTimeout{
 			Create: schema.DefaultTimeout(2 * time.Hour),
 			Read:   schema.DefaultTimeout(10 * time.Minute),
@@ -53,6 +140,90 @@ func ResourceCluster() *schema.Resource {
 	}
 }
 
+// clusterCreationOnlyAttributes the attributes of vcf_cluster that SDDC Manager applies only when
+// the cluster is created. Neither the VCF API nor vCenter Server can change them on an existing cluster.
+var clusterCreationOnlyAttributes = []string{"cluster_image_id", "vxrail_details"}
+
+// validateCreationOnlyClusterAttributes fails the plan if a creation-only attribute of an existing cluster
+// changes. The API doesn't return these attributes, so they are not in the state of an imported cluster
+// and setting them afterwards fails the plan as well.
+func validateCreationOnlyClusterAttributes(diff *schema.ResourceDiff) error {
+	if len(diff.Id()) == 0 {
+		return nil
+	}
+	for _, attributeName := range clusterCreationOnlyAttributes {
+		if !diff.HasChange(attributeName) {
+			continue
+		}
+		return fmt.Errorf("changing %q of an existing cluster is not supported, it can only be set when the "+
+			"cluster is created. Revert the change or replace the cluster. The attribute is not known for "+
+			"imported clusters and must not be set for them", attributeName)
+	}
+	return nil
+}
+
+// validateDatastoreMountChanges fails the plan if a mounted NFS or vVol datastore of an existing cluster
+// is modified or the principal datastore of the cluster is removed.
+func validateDatastoreMountChanges(diff *schema.ResourceDiff) error {
+	if len(diff.Id()) == 0 {
+		return nil
+	}
+	for _, attributeName := range cluster.SupplementalDatastoreAttributes() {
+		// the datastores are checked in the apply, if they are not known yet
+		if !diff.NewValueKnown(attributeName) {
+			return nil
+		}
+	}
+	_, _, err := cluster.CreateDatastoreMountChangesFromData(diff.Get, diff.GetChange)
+	return err
+}
+
+// clusterResourceSchemaV0 the schema of vcf_cluster with version 0, in which "host" is a list.
+func clusterResourceSchemaV0() map[string]*schema.Schema {
+	clusterResourceSchema := clusterSubresourceSchema().Schema
+	clusterResourceSchema["domain_id"] = &schema.Schema{
+		Type:         schema.TypeString,
+		Required:     true,
+		Description:  "The ID of a workload domain that the cluster belongs to",
+		ValidateFunc: validation.NoZeroValues,
+	}
+	clusterResourceSchema["stretch"] = &schema.Schema{
+		Type:     schema.TypeList,
+		Optional: true,
+		MaxItems: 1,
+		Description: "Configuration for stretching the vSAN cluster across two availability zones. " +
+			"Removing the block unstretches the cluster and removes the hosts in the secondary availability zone",
+		Elem: cluster.StretchSchema(),
+	}
+	clusterResourceSchema["deletion_protection"] = resource_utils.DeletionProtectionSchema()
+	return clusterResourceSchema
+}
+
+// resourceClusterStateUpgradeV0 migrates the "host" list to a set keyed by the host ID. Lists and sets
+// are stored in the same way in the state, so only the duplicate host entries are removed.
+func resourceClusterStateUpgradeV0(_ context.Context, rawState map[string]interface{}, _ interface{}) (map[string]interface{}, error) {
+	hostsRaw, ok := rawState["host"].([]interface{})
+	if !ok {
+		return rawState, nil
+	}
+	hostIds := make(map[string]bool)
+	var hosts []interface{}
+	for _, hostRaw := range hostsRaw {
+		host, ok := hostRaw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		hostId, _ := host["id"].(string)
+		if hostIds[hostId] {
+			continue
+		}
+		hostIds[hostId] = true
+		hosts = append(hosts, host)
+	}
+	rawState["host"] = hosts
+	return rawState, nil
+}
+
 // clusterSubresourceSchema this helper function extracts the Cluster schema, so that
 // it's made available for merging in the Domain resource schema.
 func clusterSubresourceSchema() *schema.Resource {
@@ -70,22 +241,25 @@ func clusterSubresourceSchema() *schema.Resource {
 				ValidateFunc: validation.NoZeroValues,
 			},
 			"host": {
-				Type:        schema.TypeList,
-				Required:    true,
-				Description: "List of ESXi host information from the free pool to consume in a workload domain",
-				MinItems:    2,
-				Elem:        cluster.HostSpecSchema(),
+				Type:     schema.TypeList,
+				Required: true,
+				Description: "List of ESXi host information from the free pool to consume in a workload domain. " +
+					"The minimum of 3 hosts is required for vSAN based clusters. For external storage, 2 host " +
+					"clusters are also supported.",
+				MinItems: 2,
+				Elem:     cluster.HostSpecSchema(),
 			},
 			"cluster_image_id": {
 				Type:         schema.TypeString,
 				Optional:     true,
-				Description:  "ID of the cluster image to be used with the cluster",
+				Description:  "ID of the cluster image to be used with the cluster. Can only be set when the cluster is created",
 				ValidateFunc: validation.NoZeroValues,
 			},
 			"evc_mode": {
 				Type:     schema.TypeString,
 				Optional: true,
-				Description: "EVC mode for new cluster, if needed. One among: INTEL_MEROM, " +
+				Description: "EVC mode for new cluster, if needed. Can only be set when the cluster is created. " +
+					"One among: INTEL_MEROM, " +
 					"INTEL_PENRYN, INTEL_NEALEM, INTEL_WESTMERE, INTEL_SANDYBRIDGE, " +
 					"INTEL_IVYBRIDGE, INTEL_HASWELL, INTEL_BROADWELL, INTEL_SKYLAKE, " +
 					"INTEL_CASCADELAKE, AMD_REV_E, AMD_REV_F, AMD_GREYHOUND_NO3DNOW, " +
@@ -116,7 +290,7 @@ func clusterSubresourceSchema() *schema.Resource {
 			"high_availability_enabled": {
 				Type:        schema.TypeBool,
 				Optional:    true,
-				Description: "vSphere High Availability settings for the cluster",
+				Description: "vSphere High Availability settings for the cluster. Can only be set when the cluster is created",
 			},
 			"vsan_datastore": {
 				Type:        schema.TypeList,
@@ -142,15 +316,31 @@ func clusterSubresourceSchema() *schema.Resource {
 			"nfs_datastores": {
 				Type:        schema.TypeList,
 				Optional:    true,
-				Description: "Cluster storage configuration for NFS",
+				Description: "Cluster storage configuration for NFS. Datastores can be added and removed after the creation of the cluster",
 				Elem:        datastores.NfsDatastoreSchema(),
 			},
 			"vvol_datastores": {
 				Type:        schema.TypeList,
 				Optional:    true,
-				Description: "Cluster storage configuration for VVOL",
+				Description: "Cluster storage configuration for VVOL. Datastores can be added and removed after the creation of the cluster",
 				Elem:        datastores.VvolDatastoreSchema(),
 			},
+			"network_profile": {
+				Type:     schema.TypeList,
+				Optional: true,
+				MaxItems: 1,
+				Description: "Host network configuration applied to every host in the cluster, that doesn't " +
+					"have a vmnic configuration of its own",
+				Elem: network.NetworkProfileSchema(),
+			},
+			"vxrail_details": {
+				Type:     schema.TypeList,
+				Optional: true,
+				MaxItems: 1,
+				Description: "VxRail Manager details for clusters in VxRail based VMware Cloud Foundation deployments. " +
+					"Can only be set when the cluster is created",
+				Elem: cluster.VxRailDetailsSchema(),
+			},
 			"geneve_vlan_id": {
 				Type:         schema.TypeInt,
 				Optional:     true,
@@ -191,10 +381,22 @@ func clusterSubresourceSchema() *schema.Resource {
 func resourceClusterCreate(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	vcfClient := meta.(*SddcManagerClient)
 
+	err := cluster.ApplyHostSelector(ctx, data, vcfClient.ApiClient)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	err = cluster.ResolveHostIdsInClusterData(ctx, data, vcfClient.ApiClient)
+	if err != nil {
+		return diag.FromErr(err)
+	}
 	clusterSpec, err := cluster.TryConvertResourceDataToClusterSpec(data)
 	if err != nil {
 		return diag.FromErr(err)
 	}
+	err = cluster.ValidateClusterStretchChange(data.HasChange("stretch"), data.Get, data.GetChange)
+	if err != nil {
+		return diag.FromErr(err)
+	}
 	clusterId, diagnostics := createCluster(ctx, data.Get("domain_id").(string),
 		clusterSpec, vcfClient)
 	if diagnostics != nil {
@@ -203,6 +405,14 @@ func resourceClusterCreate(ctx context.Context, data *schema.ResourceData, meta
 
 	data.SetId(clusterId)
 
+	// the cluster can only be stretched once it has been created
+	if _, ok := data.GetOk("stretch"); ok {
+		diagnostics = stretchCluster(ctx, data, vcfClient)
+		if diagnostics != nil {
+			return diagnostics
+		}
+	}
+
 	return resourceClusterRead(ctx, data, meta)
 }
 
@@ -218,25 +428,129 @@ func resourceClusterRead(ctx context.Context, data *schema.ResourceData, meta in
 	if err != nil {
 		return diag.FromErr(err)
 	}
-	clusterObj := clusterResult.Payload
 
-	_ = data.Set("primary_datastore_name", clusterObj.PrimaryDatastoreName)
-	_ = data.Set("primary_datastore_type", clusterObj.PrimaryDatastoreType)
-	_ = data.Set("is_default", clusterObj.IsDefault)
-	_ = data.Set("is_stretched", clusterObj.IsStretched)
+	err = cluster.SetClusterData(ctx, data, clusterResult.Payload, apiClient)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	return readClusterSettingsFromVcenter(ctx, data, vcfClient)
+}
 
+// readClusterSettingsFromVcenter sets the EVC mode and vSphere HA of the cluster, which only vCenter Server
+// returns. The values in the state are kept if vCenter Server can't be reached.
+func readClusterSettingsFromVcenter(ctx context.Context, data *schema.ResourceData, vcfClient *SddcManagerClient) diag.Diagnostics {
+	vcenterClient, err := vcfClient.ConnectToVcenterOfDomain(ctx, data.Get("domain_id").(string))
+	if err == nil {
+		defer vcenterClient.Logout(ctx)
+		var clusterSettings *vcenter.ClusterSettings
+		clusterSettings, err = vcenterClient.GetClusterSettings(ctx, data.Get("name").(string))
+		if err == nil {
+			_ = data.Set("evc_mode", clusterSettings.EvcMode)
+			_ = data.Set("high_availability_enabled", clusterSettings.HighAvailabilityEnabled)
+			return nil
+		}
+	}
+	return diag.Diagnostics{{
+		Severity: diag.Warning,
+		Summary:  fmt.Sprintf("Failed to read the EVC mode and vSphere HA of cluster %q", data.Id()),
+		Detail:   err.Error(),
+	}}
+}
+
+// updateClusterSettingsInVcenter applies the changes to the EVC mode and vSphere HA of the cluster in vCenter Server.
+func updateClusterSettingsInVcenter(ctx context.Context, data *schema.ResourceData, vcfClient *SddcManagerClient) diag.Diagnostics {
+	if !data.HasChanges("evc_mode", "high_availability_enabled") {
+		return nil
+	}
+	vcenterClient, err := vcfClient.ConnectToVcenterOfDomain(ctx, data.Get("domain_id").(string))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	defer vcenterClient.Logout(ctx)
+
+	clusterName := data.Get("name").(string)
+	if data.HasChange("evc_mode") {
+		log.Printf("Setting the EVC mode of Cluster %s to %q", data.Id(), data.Get("evc_mode"))
+		err = vcenterClient.SetClusterEvcMode(ctx, clusterName, data.Get("evc_mode").(string))
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
+	if data.HasChange("high_availability_enabled") {
+		log.Printf("Setting vSphere HA of Cluster %s to %t", data.Id(), data.Get("high_availability_enabled"))
+		err = vcenterClient.SetClusterHighAvailability(ctx, clusterName, data.Get("high_availability_enabled").(bool))
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
 	return nil
 }
 
 func resourceClusterUpdate(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	vcfClient := meta.(*SddcManagerClient)
 
-	clusterUpdateSpec, err := cluster.CreateClusterUpdateSpec(data, false)
+	err := cluster.ApplyHostSelector(ctx, data, vcfClient.ApiClient)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	err = cluster.ResolveHostIdsInClusterData(ctx, data, vcfClient.ApiClient)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	err = cluster.ValidateClusterStretchChange(data.HasChange("stretch"), data.Get, data.GetChange)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	oldStretchValue, newStretchValue := data.GetChange("stretch")
+	oldStretchList := oldStretchValue.([]interface{})
+	newStretchList := newStretchValue.([]interface{})
+	isStretched := len(newStretchList) > 0 && len(oldStretchList) == 0
+	isUnstretched := len(oldStretchList) > 0 && len(newStretchList) == 0 && oldStretchList[0] != nil
+
+	// unstretch before applying the other changes, so that host changes concern only a single availability zone
+	if isUnstretched {
+		for _, clusterUpdateSpec := range cluster.CreateClusterUnstretchUpdateSpecs(
+			oldStretchList[0].(map[string]interface{}), data.Get("force").(bool)) {
+			diagnostics := updateCluster(ctx, data.Id(), clusterUpdateSpec, vcfClient)
+			if diagnostics != nil {
+				return diagnostics
+			}
+		}
+	}
+
+	// hosts are added before they are removed, so that replacing a host never lowers the capacity of the cluster.
+	// Changes to the EVC mode and vSphere HA are applied in vCenter Server, the other attributes,
+	// e.g. "deletion_protection", are only stored in the state
+	clusterUpdateSpecs, err := cluster.CreateClusterUpdateSpecs(data)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	for _, clusterUpdateSpec := range clusterUpdateSpecs {
+		diagnostics := updateCluster(ctx, data.Id(), clusterUpdateSpec, vcfClient)
+		if diagnostics != nil {
+			return diagnostics
+		}
+	}
+
+	if isStretched {
+		diagnostics := stretchCluster(ctx, data, vcfClient)
+		if diagnostics != nil {
+			return diagnostics
+		}
+	}
+
+	mountSpecs, removedDatastoreNames, err := cluster.CreateDatastoreMountChangesFromData(data.Get, data.GetChange)
 	if err != nil {
 		return diag.FromErr(err)
 	}
+	diagnostics := updateClusterDatastores(ctx, data.Id(), mountSpecs, removedDatastoreNames, vcfClient)
+	if diagnostics != nil {
+		return diagnostics
+	}
 
-	diagnostics := updateCluster(ctx, data.Id(), clusterUpdateSpec, vcfClient)
+	diagnostics = updateClusterSettingsInVcenter(ctx, data, vcfClient)
 	if diagnostics != nil {
 		return diagnostics
 	}
@@ -247,6 +561,28 @@ func resourceClusterUpdate(ctx context.Context, data *schema.ResourceData, meta
 func resourceClusterDelete(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	vcfClient := meta.(*SddcManagerClient)
 
+	err := resource_utils.CheckDeletionProtection(data.Get("deletion_protection"), "cluster", data.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	// the deletion of a cluster fails if it has dead or disconnected hosts, which are removed first
+	if data.Get("force").(bool) {
+		hostsById, err := cluster.GetHostsInCluster(ctx, data.Id(), vcfClient.ApiClient)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		clusterUpdateSpec := cluster.CreateForcedContractionOfUnreachableHosts(ctx, hostsById, network.IsHostReachable)
+		if clusterUpdateSpec != nil {
+			log.Printf("Removing %d unreachable hosts from Cluster %s before its deletion",
+				len(clusterUpdateSpec.ClusterCompactionSpec.Hosts), data.Id())
+			diagnostics := updateCluster(ctx, data.Id(), clusterUpdateSpec, vcfClient)
+			if diagnostics != nil {
+				return diagnostics
+			}
+		}
+	}
+
 	diagnostics := deleteCluster(ctx, data.Id(), vcfClient)
 	if diagnostics != nil {
 		return diagnostics
@@ -300,9 +636,12 @@ func createCluster(ctx context.Context, domainId string, clusterSpec *models.Clu
 func updateCluster(ctx context.Context, clusterId string, clusterUpdateSpec *models.ClusterUpdateSpec,
 	vcfClient *SddcManagerClient) diag.Diagnostics {
 	apiClient := vcfClient.ApiClient
-	validationDiagnostics := cluster.ValidateClusterUpdateOperation(ctx, clusterId, clusterUpdateSpec, apiClient)
-	if validationDiagnostics != nil {
-		return validationDiagnostics
+	// a forced removal of hosts is meant for hosts that are unreachable, for which the validation fails
+	if clusterUpdateSpec.ClusterCompactionSpec == nil || !clusterUpdateSpec.ClusterCompactionSpec.Force {
+		validationDiagnostics := cluster.ValidateClusterUpdateOperation(ctx, clusterId, clusterUpdateSpec, apiClient)
+		if validationDiagnostics != nil {
+			return validationDiagnostics
+		}
 	}
 
 	clusterUpdateParams := clusters.NewUpdateClusterParamsWithContext(ctx).
@@ -328,6 +667,82 @@ func updateCluster(ctx context.Context, clusterId string, clusterUpdateSpec *mod
 	return nil
 }
 
+func stretchCluster(ctx context.Context, data *schema.ResourceData, vcfClient *SddcManagerClient) diag.Diagnostics {
+	clusterUpdateSpec, err := cluster.CreateClusterStretchUpdateSpec(data)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	log.Printf("Stretching Cluster %s", data.Id())
+	return updateCluster(ctx, data.Id(), clusterUpdateSpec, vcfClient)
+}
+
+// updateClusterDatastores mounts the NFS and vVol datastores added to the cluster, before unmounting the removed ones.
+func updateClusterDatastores(ctx context.Context, clusterId string, mountSpecs []*models.DatastoreMountSpec,
+	removedDatastoreNames []string, vcfClient *SddcManagerClient) diag.Diagnostics {
+	apiClient := vcfClient.ApiClient
+
+	for _, mountSpec := range mountSpecs {
+		addDatastoreParams := clusters.NewAddDatastoreToClusterParamsWithContext(ctx).
+			WithTimeout(constants.DefaultVcfApiCallTimeout)
+		addDatastoreParams.ID = clusterId
+		addDatastoreParams.DatastoreMountSpec = mountSpec
+
+		log.Printf("Mounting datastore to Cluster %s", clusterId)
+		okResponse, acceptedResponse, err := apiClient.Clusters.AddDatastoreToCluster(addDatastoreParams)
+		if err != nil {
+			return validationUtils.ConvertVcfErrorToDiag(err)
+		}
+		var taskId string
+		if okResponse != nil {
+			taskId = okResponse.Payload.ID
+		}
+		if acceptedResponse != nil {
+			taskId = acceptedResponse.Payload.ID
+		}
+		err = vcfClient.WaitForTaskComplete(ctx, taskId, false)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	if len(removedDatastoreNames) == 0 {
+		return nil
+	}
+	datastoreIdsByName, err := cluster.GetClusterDatastoreIdsByName(ctx, clusterId, apiClient)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	for _, datastoreName := range removedDatastoreNames {
+		datastoreId, ok := datastoreIdsByName[datastoreName]
+		if !ok {
+			log.Printf("Datastore %s is already unmounted from Cluster %s", datastoreName, clusterId)
+			continue
+		}
+		removeDatastoreParams := clusters.NewRemoveDatastoreFromClusterParamsWithContext(ctx).
+			WithTimeout(constants.DefaultVcfApiCallTimeout)
+		removeDatastoreParams.ID = clusterId
+		removeDatastoreParams.DatastoreID = datastoreId
+
+		log.Printf("Unmounting datastore %s from Cluster %s", datastoreName, clusterId)
+		okResponse, acceptedResponse, err := apiClient.Clusters.RemoveDatastoreFromCluster(removeDatastoreParams)
+		if err != nil {
+			return validationUtils.ConvertVcfErrorToDiag(err)
+		}
+		var taskId string
+		if okResponse != nil {
+			taskId = okResponse.Payload.ID
+		}
+		if acceptedResponse != nil {
+			taskId = acceptedResponse.Payload.ID
+		}
+		err = vcfClient.WaitForTaskComplete(ctx, taskId, false)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
+	return nil
+}
+
 func deleteCluster(ctx context.Context, clusterId string, vcfClient *SddcManagerClient) diag.Diagnostics {
 	clusterUpdateParams := clusters.NewUpdateClusterParamsWithContext(ctx).
 		WithTimeout(constants.DefaultVcfApiCallTimeout)
@@ -7,7 +7,10 @@ package provider
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"github.com/hashicorp/go-cty/cty"
+	ctyjson "github.com/hashicorp/go-cty/cty/json"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 	"github.com/vmware/terraform-provider-vcf/internal/constants"
@@ -20,12 +23,57 @@ import (
 )
 
 func TestAccResourceVcfCluster(t *testing.T) {
+	initialConfig := testAccVcfClusterResourceConfig(
+		os.Getenv(constants.VcfTestDomainDataSourceId),
+		os.Getenv(constants.VcfTestHost5Fqdn),
+		os.Getenv(constants.VcfTestHost5Pass),
+		os.Getenv(constants.VcfTestHost6Fqdn),
+		os.Getenv(constants.VcfTestHost6Pass),
+		os.Getenv(constants.VcfTestHost7Fqdn),
+		os.Getenv(constants.VcfTestHost7Pass),
+		os.Getenv(constants.VcfTestEsxiLicenseKey),
+		os.Getenv(constants.VcfTestVsanLicenseKey),
+		"",
+		"")
 	resource.ParallelTest(t, resource.TestCase{
 		PreCheck:          func() { testAccPreCheck(t) },
 		ProviderFactories: providerFactories,
 		CheckDestroy:      testCheckVcfClusterDestroy,
 		Steps: []resource.TestStep{
 			{
+				Config: initialConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrSet("vcf_cluster.cluster1", "name"),
+					resource.TestCheckResourceAttrSet("vcf_cluster.cluster1", "primary_datastore_name"),
+					resource.TestCheckResourceAttrSet("vcf_cluster.cluster1", "primary_datastore_type"),
+					resource.TestCheckResourceAttrSet("vcf_cluster.cluster1", "is_default"),
+					resource.TestCheckResourceAttrSet("vcf_cluster.cluster1", "is_stretched"),
+					resource.TestCheckResourceAttrSet("vcf_cluster.cluster1", "host.0.id"),
+					resource.TestCheckResourceAttrSet("vcf_cluster.cluster1", "host.1.id"),
+					resource.TestCheckResourceAttrSet("vcf_cluster.cluster1", "host.2.id"),
+				),
+			},
+			{
+				// the state read after the creation matches the configuration
+				Config:   initialConfig,
+				PlanOnly: true,
+			},
+			{
+				ResourceName:       "vcf_cluster.cluster1",
+				ImportState:        true,
+				ImportStatePersist: true,
+				ImportStateCheck:   clusterImportStateCheck,
+			},
+			{
+				// the VCF API doesn't return the license keys and the vmnics of the hosts, the Geneve VLAN ID and the
+				// datastore settings other than the names, and the hosts configured by ID are keyed by their FQDN
+				// in the imported state, so the imported cluster plans with a diff, see ImportCluster
+				Config:             initialConfig,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				// add another host to the cluster
 				Config: testAccVcfClusterResourceConfig(
 					os.Getenv(constants.VcfTestDomainDataSourceId),
 					os.Getenv(constants.VcfTestHost5Fqdn),
@@ -36,8 +84,13 @@ func TestAccResourceVcfCluster(t *testing.T) {
 					os.Getenv(constants.VcfTestHost7Pass),
 					os.Getenv(constants.VcfTestEsxiLicenseKey),
 					os.Getenv(constants.VcfTestVsanLicenseKey),
-					"",
-					""),
+					testAccVcfHostCommissionConfig(
+						"host4",
+						os.Getenv(constants.VcfTestHost8Fqdn),
+						os.Getenv(constants.VcfTestHost8Pass)),
+					testAccVcfHostInClusterConfig("host4",
+						os.Getenv(constants.VcfTestEsxiLicenseKey),
+						"sfo-m01-cl01")),
 				Check: resource.ComposeTestCheckFunc(
 					resource.TestCheckResourceAttrSet("vcf_cluster.cluster1", "name"),
 					resource.TestCheckResourceAttrSet("vcf_cluster.cluster1", "primary_datastore_name"),
@@ -47,15 +100,11 @@ func TestAccResourceVcfCluster(t *testing.T) {
 					resource.TestCheckResourceAttrSet("vcf_cluster.cluster1", "host.0.id"),
 					resource.TestCheckResourceAttrSet("vcf_cluster.cluster1", "host.1.id"),
 					resource.TestCheckResourceAttrSet("vcf_cluster.cluster1", "host.2.id"),
+					resource.TestCheckResourceAttrSet("vcf_cluster.cluster1", "host.3.id"),
 				),
 			},
 			{
-				ResourceName:     "vcf_cluster.cluster1",
-				ImportState:      true,
-				ImportStateCheck: clusterImportStateCheck,
-			},
-			{
-				// add another host to the cluster
+				// remove the added host
 				Config: testAccVcfClusterResourceConfig(
 					os.Getenv(constants.VcfTestDomainDataSourceId),
 					os.Getenv(constants.VcfTestHost5Fqdn),
@@ -70,9 +119,7 @@ func TestAccResourceVcfCluster(t *testing.T) {
 						"host4",
 						os.Getenv(constants.VcfTestHost8Fqdn),
 						os.Getenv(constants.VcfTestHost8Pass)),
-					testAccVcfHostInClusterConfig("host4",
-						os.Getenv(constants.VcfTestEsxiLicenseKey),
-						"sfo-m01-cl01")),
+					""),
 				Check: resource.ComposeTestCheckFunc(
 					resource.TestCheckResourceAttrSet("vcf_cluster.cluster1", "name"),
 					resource.TestCheckResourceAttrSet("vcf_cluster.cluster1", "primary_datastore_name"),
@@ -82,11 +129,10 @@ func TestAccResourceVcfCluster(t *testing.T) {
 					resource.TestCheckResourceAttrSet("vcf_cluster.cluster1", "host.0.id"),
 					resource.TestCheckResourceAttrSet("vcf_cluster.cluster1", "host.1.id"),
 					resource.TestCheckResourceAttrSet("vcf_cluster.cluster1", "host.2.id"),
-					resource.TestCheckResourceAttrSet("vcf_cluster.cluster1", "host.3.id"),
 				),
 			},
 			{
-				// remove the added host
+				// add the host again, referenced by its FQDN
 				Config: testAccVcfClusterResourceConfig(
 					os.Getenv(constants.VcfTestDomainDataSourceId),
 					os.Getenv(constants.VcfTestHost5Fqdn),
@@ -101,7 +147,9 @@ func TestAccResourceVcfCluster(t *testing.T) {
 						"host4",
 						os.Getenv(constants.VcfTestHost8Fqdn),
 						os.Getenv(constants.VcfTestHost8Pass)),
-					""),
+					testAccVcfHostInClusterByFqdnConfig("host4",
+						os.Getenv(constants.VcfTestEsxiLicenseKey),
+						"sfo-m01-cl01")),
 				Check: resource.ComposeTestCheckFunc(
 					resource.TestCheckResourceAttrSet("vcf_cluster.cluster1", "name"),
 					resource.TestCheckResourceAttrSet("vcf_cluster.cluster1", "primary_datastore_name"),
@@ -111,12 +159,177 @@ func TestAccResourceVcfCluster(t *testing.T) {
 					resource.TestCheckResourceAttrSet("vcf_cluster.cluster1", "host.0.id"),
 					resource.TestCheckResourceAttrSet("vcf_cluster.cluster1", "host.1.id"),
 					resource.TestCheckResourceAttrSet("vcf_cluster.cluster1", "host.2.id"),
+					resource.TestCheckResourceAttrSet("vcf_cluster.cluster1", "host.3.id"),
 				),
 			},
 		},
 	})
 }
 
+func TestResourceClusterStateUpgradeV0(t *testing.T) {
+	rawState := map[string]interface{}{
+		"name": "sfo-m01-cl01",
+		"host": []interface{}{
+			map[string]interface{}{"id": "host-1"},
+			map[string]interface{}{"id": "host-2"},
+			map[string]interface{}{"id": "host-1"},
+		},
+	}
+
+	upgradedState, err := resourceClusterStateUpgradeV0(context.Background(), rawState, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	hosts := upgradedState["host"].([]interface{})
+	if len(hosts) != 2 {
+		t.Fatalf("expected 2 hosts after the upgrade, got %d", len(hosts))
+	}
+	if upgradedState["name"] != "sfo-m01-cl01" {
+		t.Fatalf("cluster name was not preserved by the upgrade")
+	}
+}
+
+func TestValidateCreationOnlyClusterAttributes(t *testing.T) {
+	clusterConfig := func(attributes map[string]interface{}) map[string]interface{} {
+		result := map[string]interface{}{
+			"domain_id": "domain-1",
+			"name":      "sfo-w01-cl01",
+			"host": []interface{}{
+				map[string]interface{}{"id": "host-1"},
+				map[string]interface{}{"id": "host-2"},
+			},
+			"vds": []interface{}{map[string]interface{}{"name": "sfo-w01-cl01-vds01"}},
+		}
+		for attributeName, attributeValue := range attributes {
+			result[attributeName] = attributeValue
+		}
+		return result
+	}
+	vxRailDetails := func(dnsName string) []interface{} {
+		credentials := []interface{}{map[string]interface{}{"username": "admin", "password": "VMware123!"}}
+		return []interface{}{map[string]interface{}{
+			"dns_name":          dnsName,
+			"ip_address":        "10.0.0.250",
+			"admin_credentials": credentials,
+			"root_credentials":  credentials,
+		}}
+	}
+
+	var changeTests = []struct {
+		name        string
+		oldConfig   map[string]interface{}
+		newConfig   map[string]interface{}
+		expectedErr string
+	}{
+		{"unchanged", map[string]interface{}{"cluster_image_id": "image-1", "vxrail_details": vxRailDetails("vxrail-1.vrack.vsphere.local")},
+			map[string]interface{}{"cluster_image_id": "image-1", "vxrail_details": vxRailDetails("vxrail-1.vrack.vsphere.local")}, ""},
+		{"change cluster image", map[string]interface{}{"cluster_image_id": "image-1"},
+			map[string]interface{}{"cluster_image_id": "image-2"}, "\"cluster_image_id\""},
+		{"set cluster image after import", map[string]interface{}{},
+			map[string]interface{}{"cluster_image_id": "image-1"}, "\"cluster_image_id\""},
+		{"remove cluster image", map[string]interface{}{"cluster_image_id": "image-1"},
+			map[string]interface{}{}, "\"cluster_image_id\""},
+		{"change VxRail details", map[string]interface{}{"vxrail_details": vxRailDetails("vxrail-1.vrack.vsphere.local")},
+			map[string]interface{}{"vxrail_details": vxRailDetails("vxrail-2.vrack.vsphere.local")}, "\"vxrail_details\""},
+		{"set VxRail details after import", map[string]interface{}{},
+			map[string]interface{}{"vxrail_details": vxRailDetails("vxrail-1.vrack.vsphere.local")}, "\"vxrail_details\""},
+		{"change HA", map[string]interface{}{"high_availability_enabled": true},
+			map[string]interface{}{"high_availability_enabled": false}, ""},
+		{"change EVC mode", map[string]interface{}{"evc_mode": "INTEL_SKYLAKE"},
+			map[string]interface{}{"evc_mode": "INTEL_CASCADELAKE"}, ""},
+	}
+	for _, changeTest := range changeTests {
+		err := planResourceChange(t, ResourceCluster(), "cluster-1", clusterConfig(changeTest.oldConfig), clusterConfig(changeTest.newConfig))
+		if len(changeTest.expectedErr) == 0 {
+			if err != nil {
+				t.Errorf("%s: unexpected error %v", changeTest.name, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), changeTest.expectedErr) {
+			t.Errorf("%s: expected an error about %s, got %v", changeTest.name, changeTest.expectedErr, err)
+		}
+	}
+}
+
+func TestHostSpecPasswordVersion(t *testing.T) {
+	// the hosts in the "host" set are keyed by FQDN or ID, so only the list of the secondary availability
+	// zone hosts can show a diff for the passwords of the hosts
+	clusterConfig := func(secondaryAzHosts ...map[string]interface{}) map[string]interface{} {
+		var hostsList []interface{}
+		for _, host := range secondaryAzHosts {
+			hostsList = append(hostsList, host)
+		}
+		return map[string]interface{}{
+			"domain_id": "domain-1",
+			"name":      "sfo-w01-cl01",
+			"host": []interface{}{
+				map[string]interface{}{"id": "host-1"},
+				map[string]interface{}{"id": "host-2"},
+			},
+			"vds": []interface{}{map[string]interface{}{"name": "sfo-w01-cl01-vds01"}},
+			"stretch": []interface{}{map[string]interface{}{
+				"witness_host_fqdn":            "witness.vrack.vsphere.local",
+				"witness_host_vsan_ip":         "10.0.0.10",
+				"witness_host_vsan_cidr":       "10.0.0.0/24",
+				"secondary_az_overlay_vlan_id": 10,
+				"secondary_az_host":            hostsList,
+			}},
+		}
+	}
+	// the write-only passwords are removed from the state once the hosts are added
+	addedHosts := []map[string]interface{}{
+		{"id": "host-3", "password_version": "1"},
+		{"id": "host-4", "password_version": "1"},
+	}
+
+	var passwordTests = []struct {
+		name        string
+		oldConfig   map[string]interface{}
+		newConfig   map[string]interface{}
+		expectedErr string
+	}{
+		{"write-only passwords of added hosts", clusterConfig(addedHosts...),
+			clusterConfig(map[string]interface{}{"id": "host-3", "password_version": "1", "password": "VMware1!"},
+				map[string]interface{}{"id": "host-4", "password_version": "1", "password": "VMware1!"}), ""},
+		{"passwords of added hosts", clusterConfig(map[string]interface{}{"id": "host-3"}, map[string]interface{}{"id": "host-4"}),
+			clusterConfig(map[string]interface{}{"id": "host-3", "password": "VMware1!"},
+				map[string]interface{}{"id": "host-4"}), "modifying the stretch configuration"},
+	}
+	clusterResource := ResourceCluster()
+	toValue := func(rawConfig map[string]interface{}) cty.Value {
+		configJson, err := json.Marshal(rawConfig)
+		if err != nil {
+			t.Fatal(err)
+		}
+		value, err := ctyjson.Unmarshal(configJson, clusterResource.CoreConfigSchema().ImpliedType())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return value
+	}
+	for _, passwordTest := range passwordTests {
+		// the state holds the hosts of the old configuration, as they are after the apply
+		passwordTest.oldConfig["id"] = "cluster-1"
+		state, err := clusterResource.ShimInstanceStateFromValue(toValue(passwordTest.oldConfig))
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = clusterResource.Diff(context.Background(), state,
+			terraform.NewResourceConfigShimmed(toValue(passwordTest.newConfig), clusterResource.CoreConfigSchema()), nil)
+		if len(passwordTest.expectedErr) == 0 {
+			if err != nil {
+				t.Errorf("%s: unexpected error %v", passwordTest.name, err)
+			}
+			continue
+		}
+		// a diff in the stretch block of a stretched cluster fails the plan
+		if err == nil || !strings.Contains(err.Error(), passwordTest.expectedErr) {
+			t.Errorf("%s: expected an error about %s, got %v", passwordTest.name, passwordTest.expectedErr, err)
+		}
+	}
+}
+
 func testAccVcfHostInClusterConfig(hostResourceId, esxLicenseKey, clusterName string) string {
 	return fmt.Sprintf(
 		`host {
@@ -134,6 +347,23 @@ func testAccVcfHostInClusterConfig(hostResourceId, esxLicenseKey, clusterName st
 	`, hostResourceId, esxLicenseKey, clusterName, clusterName)
 }
 
+func testAccVcfHostInClusterByFqdnConfig(hostResourceId, esxLicenseKey, clusterName string) string {
+	return fmt.Sprintf(
+		`host {
+		fqdn = vcf_host.%s.fqdn
+		license_key = %q
+		vmnic {
+			id = "vmnic0"
+			vds_name = "%s-vds01"
+		}
+		vmnic {
+			id = "vmnic1"
+			vds_name = "%s-vds01"
+		}
+	}
+	`, hostResourceId, esxLicenseKey, clusterName, clusterName)
+}
+
 func testAccVcfHostCommissionConfig(hostResourceId, hostFqdn, hostPass string) string {
 	return fmt.Sprintf(`
 	resource "vcf_host" %q {
@@ -142,6 +372,7 @@ func testAccVcfHostCommissionConfig(hostResourceId, hostFqdn, hostPass string) s
 		password  = %q
 		network_pool_id = vcf_network_pool.domain_pool.id
 		storage_type = "VSAN"
+		deletion_protection = false
 	}
 	`, hostResourceId, hostFqdn, hostPass)
 }
@@ -184,6 +415,7 @@ func testAccVcfClusterResourceConfig(domainId, host1Fqdn, host1Pass, host2Fqdn,
 		password  = %q
 		network_pool_id = vcf_network_pool.domain_pool.id
 		storage_type = "VSAN"
+		deletion_protection = false
 	}
 	resource "vcf_host" "host2" {
 		fqdn      = %q
@@ -191,6 +423,7 @@ func testAccVcfClusterResourceConfig(domainId, host1Fqdn, host1Pass, host2Fqdn,
 		password  = %q
 		network_pool_id = vcf_network_pool.domain_pool.id
 		storage_type = "VSAN"
+		deletion_protection = false
 	}
 	resource "vcf_host" "host3" {
 		fqdn      = %q
@@ -198,10 +431,12 @@ func testAccVcfClusterResourceConfig(domainId, host1Fqdn, host1Pass, host2Fqdn,
 		password  = %q
 		network_pool_id = vcf_network_pool.domain_pool.id
 		storage_type = "VSAN"
+		deletion_protection = false
 	}
 	%s
 	resource "vcf_cluster" "cluster1" {
 		domain_id = %q
+		deletion_protection = false
 		name = "sfo-m01-cl01"
 		host {
 			id = vcf_host.host1.id
@@ -316,6 +551,12 @@ func clusterImportStateCheck(states []*terraform.InstanceState) error {
 		if state.Attributes["primary_datastore_type"] != "VSAN" {
 			return fmt.Errorf("cluster has wrong primary_datastore_type attribute set")
 		}
+		if state.Attributes["vsan_datastore.0.datastore_name"] != "sfo-m01-cl01-ds-vsan01" {
+			return fmt.Errorf("cluster has wrong vsan_datastore.0.datastore_name attribute set")
+		}
+		if state.Attributes["deletion_protection"] != "true" {
+			return fmt.Errorf("cluster has wrong deletion_protection attribute set")
+		}
 		if validationUtils.IsEmpty(state.Attributes["is_default"]) {
 			return fmt.Errorf("cluster has no is_default attribute set")
 		}
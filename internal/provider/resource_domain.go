@@ -4,8 +4,10 @@
 package provider
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"github.com/hashicorp/go-cty/cty"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -21,15 +23,30 @@ import (
 	"github.com/vmware/vcf-sdk-go/client/domains"
 	"github.com/vmware/vcf-sdk-go/models"
 	"reflect"
+	"sort"
 	"time"
 )
 
+const (
+	// domainOnFailureRetry retries the failed domain creation task, and returns an error
+	// without storing the domain in the state, if all retries fail.
+	domainOnFailureRetry = "retry"
+	// domainOnFailureKeep stores the ID of the partially created domain in the state and
+	// marks the resource as tainted.
+	domainOnFailureKeep = "keep"
+	// domainOnFailureCleanup marks the partially created domain for deletion and removes it.
+	domainOnFailureCleanup = "cleanup"
+)
+
 func ResourceDomain() *schema.Resource {
 	return &schema.Resource{
 		CreateContext: resourceDomainCreate,
 		ReadContext:   resourceDomainRead,
 		UpdateContext: resourceDomainUpdate,
 		DeleteContext: resourceDomainDelete,
+		CustomizeDiff: func(_ context.Context, diff *schema.ResourceDiff, _ interface{}) error {
+			return validateClusterChangesInDomain(diff)
+		},
 		// TODO implement wld import, but fail import of Management domain
 		Timeouts: &schema.ResourceTimeout{
 			Create: schema.DefaultTimeout(4 * time.Hour),
@@ -37,74 +54,146 @@ func ResourceDomain() *schema.Resource {
 			Update: schema.DefaultTimeout(4 * time.Hour),
 			Delete: schema.DefaultTimeout(1 * time.Hour),
 		},
-		Schema: map[string]*schema.Schema{
-			"name": {
-				Type:         schema.TypeString,
-				Required:     true,
-				ValidateFunc: validation.StringLenBetween(3, 20),
-				Description:  "Name of the domain (from 3 to 20 characters)",
-			},
-			"org_name": {
-				Type:         schema.TypeString,
-				Optional:     true,
-				ValidateFunc: validation.StringLenBetween(3, 20),
-				Description:  "Organization name of the workload domain",
-			},
-			"vcenter": {
-				Type:        schema.TypeList,
-				Required:    true,
-				Description: "Specification describing vCenter Server instance settings",
-				MinItems:    1,
-				MaxItems:    1,
-				Elem:        vcenter.VCSubresourceSchema(),
-			},
-			"nsx_configuration": {
-				Type:        schema.TypeList,
-				Optional:    true,
-				Description: "Specification details for NSX configuration",
-				MaxItems:    1,
-				Elem:        network.NsxSchema(),
-			},
-			"cluster": {
-				Type:        schema.TypeList,
-				Required:    true,
-				Description: "Specification representing the clusters to be added to the workload domain",
-				MinItems:    1,
-				Elem:        clusterSubresourceSchema(),
-			},
-			"status": {
-				Type:        schema.TypeString,
-				Computed:    true,
-				Description: "Status of the workload domain",
-			},
-			"type": {
-				Type:        schema.TypeString,
-				Computed:    true,
-				Description: "Type of the workload domain",
-			},
-			"sso_id": {
-				Type:        schema.TypeString,
-				Computed:    true,
-				Description: "ID of the SSO domain associated with the workload domain",
-			},
-			"sso_name": {
-				Type:        schema.TypeString,
-				Computed:    true,
-				Description: "Name of the SSO domain associated with the workload domain",
-			},
-			"is_management_sso_domain": {
-				Type:        schema.TypeBool,
-				Computed:    true,
-				Description: "Shows whether the workload domain is joined to the management domain SSO",
+		SchemaVersion: 1,
+		StateUpgraders: []schema.StateUpgrader{
+			{
+				Type:    (&schema.Resource{Schema: domainResourceSchemaV0()}).CoreConfigSchema().ImpliedType(),
+				Upgrade: resourceDomainStateUpgradeV0,
+				Version: 0,
 			},
 		},
+		Schema: domainResourceSchema(),
 	}
 }
 
+func domainResourceSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"name": {
+			Type:         schema.TypeString,
+			Required:     true,
+			ValidateFunc: validation.StringLenBetween(3, 20),
+			Description:  "Name of the domain (from 3 to 20 characters)",
+		},
+		"org_name": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			ValidateFunc: validation.StringLenBetween(3, 20),
+			Description:  "Organization name of the workload domain",
+		},
+		"vcenter": {
+			Type:        schema.TypeList,
+			Required:    true,
+			Description: "Specification describing vCenter Server instance settings",
+			MinItems:    1,
+			MaxItems:    1,
+			Elem:        vcenter.VCSubresourceSchema(),
+		},
+		"nsx_configuration": {
+			Type:        schema.TypeList,
+			Optional:    true,
+			Description: "Specification details for NSX configuration",
+			MaxItems:    1,
+			Elem:        network.NsxSchema(),
+		},
+		"cluster": {
+			Type:     schema.TypeSet,
+			Required: true,
+			Description: "Specification representing the clusters to be added to the workload domain. " +
+				"Clusters are identified by their name, so changing the order of the cluster blocks has no effect",
+			MinItems: 1,
+			Set:      hashDomainCluster,
+			Elem:     clusterSubresourceSchema(),
+		},
+		"on_failure": {
+			Type:     schema.TypeString,
+			Optional: true,
+			Default:  domainOnFailureRetry,
+			Description: "What to do if the creation of the domain fails after it has been started. One among: " +
+				"retry - retry the failed creation task and report an error if it still fails, " +
+				"keep - store the partially created domain in the state and mark it as tainted, " +
+				"cleanup - delete the partially created domain, so that the creation can be re-run",
+			ValidateFunc: validation.StringInSlice([]string{
+				domainOnFailureRetry, domainOnFailureKeep, domainOnFailureCleanup}, false),
+		},
+		"deletion_protection": resource_utils.DeletionProtectionSchema(),
+		"status": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "Status of the workload domain",
+		},
+		"type": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "Type of the workload domain",
+		},
+		"sso_id": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "ID of the SSO domain associated with the workload domain",
+		},
+		"sso_name": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "Name of the SSO domain associated with the workload domain",
+		},
+		"is_management_sso_domain": {
+			Type:        schema.TypeBool,
+			Computed:    true,
+			Description: "Shows whether the workload domain is joined to the management domain SSO",
+		},
+	}
+}
+
+// domainResourceSchemaV0 the schema before the clusters were modelled as a set, identified by their
+// configuration instead of their position.
+func domainResourceSchemaV0() map[string]*schema.Schema {
+	domainSchema := domainResourceSchema()
+	domainSchema["cluster"] = &schema.Schema{
+		Type:        schema.TypeList,
+		Required:    true,
+		Description: "Specification representing the clusters to be added to the workload domain",
+		MinItems:    1,
+		Elem:        clusterSubresourceSchema(),
+	}
+	return domainSchema
+}
+
+// resourceDomainStateUpgradeV0 migrates the "cluster" list to a set. Lists and sets are stored in the
+// same way in the state, so only the duplicate cluster entries, with the same name, are removed.
+func resourceDomainStateUpgradeV0(_ context.Context, rawState map[string]interface{}, _ interface{}) (map[string]interface{}, error) {
+	clustersRaw, ok := rawState["cluster"].([]interface{})
+	if !ok {
+		return rawState, nil
+	}
+	clusterNames := make(map[string]bool)
+	var clusters []interface{}
+	for _, clusterRaw := range clustersRaw {
+		clusterObject, ok := clusterRaw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		clusterName, _ := clusterObject["name"].(string)
+		if clusterNames[clusterName] {
+			continue
+		}
+		clusterNames[clusterName] = true
+		clusters = append(clusters, clusterObject)
+	}
+	rawState["cluster"] = clusters
+	return rawState, nil
+}
+
 func resourceDomainCreate(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	vcfClient := meta.(*SddcManagerClient)
 	apiClient := vcfClient.ApiClient
 
+	clustersList := data.Get("cluster").(*schema.Set).List()
+	err := resolveHostIdsInClusters(ctx, clustersList, nil, apiClient)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	_ = data.Set("cluster", clustersList)
+
 	domainCreationSpec, err := createDomainCreationSpec(data)
 	if err != nil {
 		return diag.FromErr(err)
@@ -130,9 +219,10 @@ func resourceDomainCreate(ctx context.Context, data *schema.ResourceData, meta i
 		return validationUtils.ConvertVcfErrorToDiag(err)
 	}
 	taskId := accepted.Payload.ID
-	err = vcfClient.WaitForTaskComplete(ctx, taskId, true)
+	onFailure := data.Get("on_failure").(string)
+	err = vcfClient.WaitForTaskComplete(ctx, taskId, onFailure == domainOnFailureRetry)
 	if err != nil {
-		return diag.FromErr(err)
+		return handleDomainCreationFailure(ctx, data, taskId, onFailure, err, vcfClient)
 	}
 	domainId, err := vcfClient.GetResourceIdAssociatedWithTask(ctx, taskId, "Domain")
 	if err != nil {
@@ -144,6 +234,50 @@ func resourceDomainCreate(ctx context.Context, data *schema.ResourceData, meta i
 	return resourceDomainRead(ctx, data, meta)
 }
 
+// handleDomainCreationFailure applies the "on_failure" policy to a domain, whose creation
+// task has failed.
+func handleDomainCreationFailure(ctx context.Context, data *schema.ResourceData, taskId, onFailure string,
+	taskErr error, vcfClient *SddcManagerClient) diag.Diagnostics {
+	diags := diag.FromErr(taskErr)
+	if onFailure == domainOnFailureRetry {
+		return diags
+	}
+
+	domainId, err := vcfClient.GetResourceIdAssociatedWithTask(ctx, taskId, "Domain")
+	if err != nil {
+		// the task might have failed before associating the domain with it
+		domain, getDomainErr := getDomainByName(ctx, data.Get("name").(string), vcfClient.ApiClient)
+		if getDomainErr != nil {
+			tflog.Warn(ctx, fmt.Sprintf("Could not find partially created domain: %s, %s", err, getDomainErr))
+			return diags
+		}
+		domainId = domain.ID
+	}
+
+	switch onFailure {
+	case domainOnFailureKeep:
+		// Terraform marks the resource as tainted, since it has an ID and the creation has failed.
+		// The partially created domain isn't protected from deletion, so that it can be replaced.
+		data.SetId(domainId)
+		_ = data.Set("deletion_protection", false)
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary:  fmt.Sprintf("Partially created domain %q has been kept", domainId),
+			Detail:   "The domain has been stored in the state as tainted and will be replaced on the next apply",
+		})
+	case domainOnFailureCleanup:
+		tflog.Info(ctx, fmt.Sprintf("Cleaning up partially created domain %q", domainId))
+		cleanupDiags := deleteDomain(ctx, domainId, vcfClient)
+		if cleanupDiags != nil {
+			// keep the domain in the state, so that it isn't orphaned
+			data.SetId(domainId)
+			_ = data.Set("deletion_protection", false)
+			diags = append(diags, cleanupDiags...)
+		}
+	}
+	return diags
+}
+
 func resourceDomainRead(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	vcfClient := meta.(*SddcManagerClient)
 	apiClient := vcfClient.ApiClient
@@ -183,107 +317,320 @@ func resourceDomainRead(ctx context.Context, data *schema.ResourceData, meta int
 
 func resourceDomainUpdate(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	vcfClient := meta.(*SddcManagerClient)
-	apiClient := vcfClient.ApiClient
 
 	// Domain Update API supports only changes to domain name and Cluster Import
 	if data.HasChange("name") {
-		domainUpdateSpec := createDomainUpdateSpec(data, false)
-		domainUpdateParams := domains.NewUpdateDomainParamsWithContext(ctx).
-			WithTimeout(constants.DefaultVcfApiCallTimeout)
-		domainUpdateParams.DomainUpdateSpec = domainUpdateSpec
-		domainUpdateParams.ID = data.Id()
-
-		_, accepted, err := apiClient.Domains.UpdateDomain(domainUpdateParams)
-		if err != nil {
-			return diag.FromErr(err)
-		}
-		taskId := accepted.Payload.ID
-		err = vcfClient.WaitForTaskComplete(ctx, taskId, false)
-		if err != nil {
-			return diag.FromErr(err)
+		diags := updateDomain(ctx, data.Id(), createDomainUpdateSpec(data, false), vcfClient)
+		if diags != nil {
+			return diags
 		}
 	}
 
+	var diags diag.Diagnostics
 	if data.HasChange("cluster") {
 		oldClustersValue, newClustersValue := data.GetChange("cluster")
-		newClustersList := newClustersValue.([]interface{})
-		oldClustersList := oldClustersValue.([]interface{})
-		if len(oldClustersList) == len(newClustersList) {
-			diags := handleClusterUpdateInDomain(ctx, newClustersList, oldClustersList, vcfClient)
-			if diags != nil {
-				return diags
-			}
-		} else {
-			diags := handleClusterAddRemoveToDomain(ctx, data.Id(), newClustersList, oldClustersList, vcfClient)
-			if diags != nil {
-				return diags
-			}
+		newClustersList := newClustersValue.(*schema.Set).List()
+		oldClustersList := oldClustersValue.(*schema.Set).List()
+		// removing clusters is guarded by the deletion protection of the domain, as it has been applied
+		deletionProtection, _ := data.GetChange("deletion_protection")
+		diags = handleClusterChangesInDomain(ctx, data.Id(), newClustersList, oldClustersList,
+			deletionProtection.(bool), vcfClient)
+		if diags.HasError() {
+			return diags
 		}
+		// store the IDs of the resolved hosts
+		_ = data.Set("cluster", newClustersList)
 	}
 
-	return resourceDomainRead(ctx, data, meta)
+	return append(diags, resourceDomainRead(ctx, data, meta)...)
 }
 
-func handleClusterAddRemoveToDomain(ctx context.Context, domainId string, newClustersList, oldClustersList []interface{},
+func updateDomain(ctx context.Context, domainId string, domainUpdateSpec *models.DomainUpdateSpec,
 	vcfClient *SddcManagerClient) diag.Diagnostics {
-	addedClustersList, removedClustersList := resource_utils.CalculateAddedRemovedResources(newClustersList, oldClustersList)
-	for _, addedCluster := range addedClustersList {
-		clusterSpec, err := cluster.TryConvertToClusterSpec(addedCluster)
+	apiClient := vcfClient.ApiClient
+	domainUpdateParams := domains.NewUpdateDomainParamsWithContext(ctx).
+		WithTimeout(constants.DefaultVcfApiCallTimeout)
+	domainUpdateParams.DomainUpdateSpec = domainUpdateSpec
+	domainUpdateParams.ID = domainId
+
+	acceptedUpdateTask, acceptedUpdateTask2, err := apiClient.Domains.UpdateDomain(domainUpdateParams)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	var taskId string
+	if acceptedUpdateTask != nil {
+		taskId = acceptedUpdateTask.Payload.ID
+	}
+	if acceptedUpdateTask2 != nil {
+		taskId = acceptedUpdateTask2.Payload.ID
+	}
+	err = vcfClient.WaitForTaskComplete(ctx, taskId, false)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	return nil
+}
+
+// handleClusterChangesInDomain matches the old and new clusters in the domain by name and
+// applies the changes in the following order: creates the added clusters, updates the
+// modified ones and finally deletes the removed clusters.
+func handleClusterChangesInDomain(ctx context.Context, domainId string, newClustersList, oldClustersList []interface{},
+	deletionProtection bool, vcfClient *SddcManagerClient) diag.Diagnostics {
+	err := validateClusterUpdatesInDomain(oldClustersList, newClustersList)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	err = resolveHostIdsInClusters(ctx, newClustersList, oldClustersList, vcfClient.ApiClient)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	oldClustersMap := resource_utils.CreateKeyToObjectMap(oldClustersList, "name")
+	newClustersMap := resource_utils.CreateKeyToObjectMap(newClustersList, "name")
+
+	var addedClusterNames, modifiedClusterNames, removedClusterNames []string
+	for clusterName, newCluster := range newClustersMap {
+		oldCluster, isPresent := oldClustersMap[clusterName]
+		if !isPresent {
+			addedClusterNames = append(addedClusterNames, clusterName)
+		} else if !reflect.DeepEqual(oldCluster, newCluster) {
+			modifiedClusterNames = append(modifiedClusterNames, clusterName)
+		}
+	}
+	for clusterName := range oldClustersMap {
+		if _, isPresent := newClustersMap[clusterName]; !isPresent {
+			removedClusterNames = append(removedClusterNames, clusterName)
+		}
+	}
+	// Sort the names, to have a deterministic order of the operations in every apply
+	sort.Strings(addedClusterNames)
+	sort.Strings(modifiedClusterNames)
+	sort.Strings(removedClusterNames)
+
+	// check before applying any of the changes, so that the domain isn't left partially updated
+	for _, clusterName := range removedClusterNames {
+		clusterId := oldClustersMap[clusterName]["id"].(string)
+		err := resource_utils.CheckDeletionProtection(deletionProtection, "cluster", clusterId)
+		if err != nil {
+			return diag.FromErr(fmt.Errorf("cannot remove cluster %q from domain %q: %w", clusterName, domainId, err))
+		}
+	}
+
+	for _, clusterName := range addedClusterNames {
+		clusterSpec, err := cluster.TryConvertToClusterSpec(newClustersMap[clusterName])
 		if err != nil {
 			return diag.FromErr(err)
 		}
 		// subsequent domain read will set the cluster ID, so we can discard it here
+		tflog.Info(ctx, fmt.Sprintf("Adding cluster %q to domain %q", clusterName, domainId))
 		_, diags := createCluster(ctx, domainId, clusterSpec, vcfClient)
 		if diags != nil {
 			return diags
 		}
 	}
 
-	for _, removedCluster := range removedClustersList {
-		clusterId := removedCluster["id"].(string)
-		diags := deleteCluster(ctx, clusterId, vcfClient)
-		if diags != nil {
+	var diags diag.Diagnostics
+	for _, clusterName := range modifiedClusterNames {
+		updateDiags := handleClusterUpdateInDomain(ctx, oldClustersMap[clusterName], newClustersMap[clusterName], vcfClient)
+		diags = append(diags, updateDiags...)
+		if diags.HasError() {
 			return diags
 		}
 	}
 
+	for _, clusterName := range removedClusterNames {
+		clusterId := oldClustersMap[clusterName]["id"].(string)
+		tflog.Info(ctx, fmt.Sprintf("Removing cluster %q from domain %q", clusterName, domainId))
+		deleteDiags := deleteCluster(ctx, clusterId, vcfClient)
+		if deleteDiags != nil {
+			return append(diags, deleteDiags...)
+		}
+	}
+
+	return diags
+}
+
+// resolveHostIdsInClusters resolves the IDs of the hosts referenced by FQDN in the provided clusters
+// and pins the SSH and SSL thumbprints of the hosts in the added and changed clusters.
+// The hosts of a cluster present in oldClustersList keep their IDs and the unchanged clusters keep
+// their pinned thumbprints, without connecting to their hosts.
+func resolveHostIdsInClusters(ctx context.Context, newClustersList, oldClustersList []interface{},
+	apiClient *client.VcfClient) error {
+	oldClustersMap := resource_utils.CreateKeyToObjectMap(oldClustersList, "name")
+	for _, newClusterRaw := range newClustersList {
+		newCluster := newClusterRaw.(map[string]interface{})
+		var existingHostsList []interface{}
+		oldCluster, isPresent := oldClustersMap[newCluster["name"].(string)]
+		if isPresent {
+			existingHostsList, _ = oldCluster["host"].([]interface{})
+		}
+		clusterId, _ := newCluster["id"].(string)
+		err := cluster.ResolveHostIds(ctx, newCluster["host"].([]interface{}), existingHostsList,
+			clusterId, cluster.GetPrincipalStorageType(newCluster), apiClient)
+		if err != nil {
+			return err
+		}
+		if isPresent && hashDomainCluster(oldCluster) == hashDomainCluster(newCluster) {
+			cluster.KeepPinnedThumbprints(newCluster["host"].([]interface{}), existingHostsList)
+			continue
+		}
+		if err = cluster.PinHostThumbprints(ctx, newCluster["host"].([]interface{}), existingHostsList); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
-func handleClusterUpdateInDomain(ctx context.Context, newClustersStateList, oldClustersStateList []interface{},
+func handleClusterUpdateInDomain(ctx context.Context, oldClusterState, newClusterState map[string]interface{},
 	vcfClient *SddcManagerClient) diag.Diagnostics {
-	if len(oldClustersStateList) != len(newClustersStateList) {
-		return diag.FromErr(fmt.Errorf("expecting old and new cluster list to have the same length"))
+	clusterName := newClusterState["name"].(string)
+	// the ID is computed, so it is always taken from the old state
+	clusterId := oldClusterState["id"].(string)
+
+	// the changes of the other attributes are rejected by validateClusterChangesInDomain
+	mountSpecs, removedDatastoreNames, err := cluster.CreateDatastoreMountChanges(oldClusterState, newClusterState)
+	if err != nil {
+		return diag.FromErr(err)
 	}
-	for i, newClusterState := range newClustersStateList {
-		// skip the clusters that have no changes
-		if reflect.DeepEqual(newClusterState, oldClustersStateList[i]) {
-			continue
+
+	oldHostsList := oldClusterState["host"].([]interface{})
+	newHostsList := newClusterState["host"].([]interface{})
+	if reflect.DeepEqual(oldHostsList, newHostsList) {
+		return updateClusterDatastores(ctx, clusterId, mountSpecs, removedDatastoreNames, vcfClient)
+	}
+
+	expansionSpec, contractionSpec, err := cluster.CreateExpansionAndContractionSpecs(oldHostsList, newHostsList)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if expansionSpec != nil {
+		err = cluster.ApplyNetworkProfile(expansionSpec.ClusterExpansionSpec.HostSpecs, newClusterState)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		tflog.Info(ctx, fmt.Sprintf("Adding hosts to cluster %q", clusterName))
+		updateDiags := updateCluster(ctx, clusterId, expansionSpec, vcfClient)
+		if updateDiags != nil {
+			return updateDiags
 		}
-		oldClusterStateMap := oldClustersStateList[i].(map[string]interface{})
-		newClusterStateMap := newClusterState.(map[string]interface{})
-		// sanity check that we're comparing the same clusters for changes to their hosts
-		newClusterStateId := newClusterStateMap["id"].(string)
-		oldClusterStateId := oldClusterStateMap["id"].(string)
-		if newClusterStateId != oldClusterStateId {
-			return diag.FromErr(fmt.Errorf("cluster order has changed, updating hosts in cluster not supported"))
+	}
+	if contractionSpec != nil {
+		// the removal is never forced, dead or disconnected hosts can only be removed by vcf_cluster
+		tflog.Info(ctx, fmt.Sprintf("Removing hosts from cluster %q", clusterName))
+		updateDiags := updateCluster(ctx, clusterId, contractionSpec, vcfClient)
+		if updateDiags != nil {
+			return updateDiags
 		}
-		oldHostsList := oldClusterStateMap["host"].([]interface{})
-		newHostsList := newClusterStateMap["host"].([]interface{})
-		if reflect.DeepEqual(oldHostsList, newHostsList) {
-			tflog.Warn(ctx, "only expand/contract cluster update is supported")
+	}
+	return updateClusterDatastores(ctx, clusterId, mountSpecs, removedDatastoreNames, vcfClient)
+}
+
+// hashDomainCluster identifies the clusters of a domain by their configuration, so that changing the
+// order of the cluster blocks produces no diff. The hosts are identified only by their FQDN or ID, since
+// the state holds the hashes of their passwords and the IDs resolved from their FQDNs, and the computed
+// attributes are left out, as they are not known in the configuration.
+func hashDomainCluster(v interface{}) int {
+	clusterObject := v.(map[string]interface{})
+	clusterResource := clusterSubresourceSchema()
+	delete(clusterResource.Schema, "host")
+
+	var buf bytes.Buffer
+	schema.SerializeResourceForHash(&buf, clusterObject, clusterResource)
+	hostsList, _ := clusterObject["host"].([]interface{})
+	for _, hostRaw := range hostsList {
+		if host, ok := hostRaw.(map[string]interface{}); ok {
+			buf.WriteString(fmt.Sprintf("host:%d;", resource_utils.HashByKey("fqdn", "id")(host)))
+		}
+	}
+	return schema.HashString(buf.String())
+}
+
+// validateClusterChangesInDomain fails the plan if an attribute of an existing cluster in the domain
+// changes, that SDDC Manager applies only when the cluster is created. Only the hosts and the NFS and
+// vVol datastores of an existing cluster can be changed.
+// The nested blocks of the set elements can't be read from the ResourceDiff, so the new clusters are
+// read from the raw configuration. If it holds values that are known only after apply, e.g. the IDs of
+// hosts commissioned in the same run, the clusters are validated by handleClusterChangesInDomain instead.
+func validateClusterChangesInDomain(diff *schema.ResourceDiff) error {
+	if len(diff.Id()) == 0 || !diff.HasChange("cluster") {
+		return nil
+	}
+	newClustersList, err := getClustersFromConfig(diff)
+	if newClustersList == nil || err != nil {
+		return err
+	}
+	oldClustersValue, _ := diff.GetChange("cluster")
+	return validateClusterUpdatesInDomain(oldClustersValue.(*schema.Set).List(), newClustersList)
+}
+
+// getClustersFromConfig returns the configured clusters, with the attributes that depend on the state,
+// e.g. the hashes of the passwords, left out. It returns nil if the clusters are not known until the apply.
+func getClustersFromConfig(diff *schema.ResourceDiff) ([]interface{}, error) {
+	rawConfig := diff.GetRawConfig()
+	if rawConfig.IsNull() || !rawConfig.IsKnown() {
+		return nil, nil
+	}
+	clustersConfig := rawConfig.GetAttr("cluster")
+	if !clustersConfig.IsWhollyKnown() {
+		return nil, nil
+	}
+	clustersResource := &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"cluster": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Set:      hashDomainCluster,
+				Elem:     clusterSubresourceSchema(),
+			},
+		},
+	}
+	// the shimmed state holds no attributes without an ID, which new resources don't have yet
+	id := diff.Id()
+	if len(id) == 0 {
+		id = "new"
+	}
+	configState, err := clustersResource.ShimInstanceStateFromValue(
+		cty.ObjectVal(map[string]cty.Value{"id": cty.StringVal(id), "cluster": clustersConfig}))
+	if err != nil {
+		return nil, err
+	}
+	return clustersResource.Data(configState).Get("cluster").(*schema.Set).List(), nil
+}
+
+// validateClusterUpdatesInDomain returns an error if an attribute of a cluster, present in both the old
+// and the new clusters list, changes and the change can't be applied to an existing cluster, e.g. a
+// mounted datastore is modified.
+func validateClusterUpdatesInDomain(oldClustersList, newClustersList []interface{}) error {
+	clusterSchema := clusterSubresourceSchema().Schema
+	attributeNames := make([]string, 0, len(clusterSchema))
+	for attributeName, attributeSchema := range clusterSchema {
+		if attributeName == "host" || cluster.IsSupplementalDatastoreAttribute(attributeName) ||
+			(attributeSchema.Computed && !attributeSchema.Optional) {
 			continue
 		}
+		attributeNames = append(attributeNames, attributeName)
+	}
+	// Sort the names, to report the same attribute in every plan
+	sort.Strings(attributeNames)
 
-		clusterUpdateSpec := new(models.ClusterUpdateSpec)
-		populatedClusterUpdateSpec, err := cluster.SetExpansionOrContractionSpec(clusterUpdateSpec, oldHostsList, newHostsList)
+	oldClustersMap := resource_utils.CreateKeyToObjectMap(oldClustersList, "name")
+	for _, newClusterRaw := range newClustersList {
+		newCluster := newClusterRaw.(map[string]interface{})
+		clusterName := newCluster["name"].(string)
+		oldCluster, isPresent := oldClustersMap[clusterName]
+		if !isPresent {
+			continue
+		}
+		_, _, err := cluster.CreateDatastoreMountChanges(oldCluster, newCluster)
 		if err != nil {
-			return diag.FromErr(err)
+			return err
 		}
-
-		diags := updateCluster(ctx, newClusterStateId, populatedClusterUpdateSpec, vcfClient)
-		if diags != nil {
-			return diags
+		for _, attributeName := range attributeNames {
+			if !reflect.DeepEqual(oldCluster[attributeName], newCluster[attributeName]) {
+				return fmt.Errorf("changing %q of the existing cluster %q is not supported, only its hosts and "+
+					"its NFS and vVol datastores can be changed. Revert the change or replace the cluster",
+					attributeName, clusterName)
+			}
 		}
 	}
 	return nil
@@ -291,32 +638,32 @@ func handleClusterUpdateInDomain(ctx context.Context, newClustersStateList, oldC
 
 func resourceDomainDelete(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	vcfClient := meta.(*SddcManagerClient)
-	apiClient := vcfClient.ApiClient
 
-	markForDeleteUpdateSpec := createDomainUpdateSpec(data, true)
-	domainUpdateParams := domains.NewUpdateDomainParamsWithContext(ctx).
-		WithTimeout(constants.DefaultVcfApiCallTimeout)
-	domainUpdateParams.DomainUpdateSpec = markForDeleteUpdateSpec
-	domainUpdateParams.ID = data.Id()
-
-	acceptedUpdateTask, _, err := apiClient.Domains.UpdateDomain(domainUpdateParams)
+	err := resource_utils.CheckDeletionProtection(data.Get("deletion_protection"), "domain", data.Id())
 	if err != nil {
 		return diag.FromErr(err)
 	}
-	taskId := acceptedUpdateTask.Payload.ID
-	err = vcfClient.WaitForTaskComplete(ctx, taskId, false)
-	if err != nil {
-		return diag.FromErr(err)
+
+	return deleteDomain(ctx, data.Id(), vcfClient)
+}
+
+func deleteDomain(ctx context.Context, domainId string, vcfClient *SddcManagerClient) diag.Diagnostics {
+	apiClient := vcfClient.ApiClient
+
+	diags := updateDomain(ctx, domainId, createDomainUpdateSpec(nil, true), vcfClient)
+	if diags != nil {
+		return diags
 	}
 
 	domainDeleteParams := domains.NewDeleteDomainParamsWithContext(ctx).
 		WithTimeout(constants.DefaultVcfApiCallTimeout)
-	domainDeleteParams.ID = data.Id()
+	domainDeleteParams.ID = domainId
 
 	acceptedDeleteTask, acceptedDeleteTask2, err := apiClient.Domains.DeleteDomain(domainDeleteParams)
 	if err != nil {
 		return diag.FromErr(err)
 	}
+	var taskId string
 	if acceptedDeleteTask != nil {
 		taskId = acceptedDeleteTask.Payload.ID
 	}
@@ -378,8 +725,7 @@ func readAndSetClustersDataToDomainResource(domainClusterRefs []*models.ClusterR
 	if err != nil {
 		return err
 	}
-	domainClusterData := data.Get("cluster")
-	domainClusterDataList := domainClusterData.([]interface{})
+	domainClusterDataList := data.Get("cluster").(*schema.Set).List()
 	allClusters := clustersResult.Payload.Elements
 	for _, domainClusterRaw := range domainClusterDataList {
 		domainCluster := domainClusterRaw.(map[string]interface{})
@@ -397,8 +743,10 @@ func readAndSetClustersDataToDomainResource(domainClusterRefs []*models.ClusterR
 				domainCluster["is_stretched"] = clusterObj.IsStretched
 			}
 		}
+		hostsList, _ := domainCluster["host"].([]interface{})
+		domainCluster["host"] = cluster.HashHostPasswords(hostsList)
 	}
-	_ = data.Set("cluster", domainClusterData)
+	_ = data.Set("cluster", domainClusterDataList)
 
 	return nil
 }
@@ -446,8 +794,8 @@ func generateVcenterSpecFromResourceData(data *schema.ResourceData) (*models.Vce
 }
 
 func generateComputeSpecFromResourceData(data *schema.ResourceData) (*models.ComputeSpec, error) {
-	if clusterConfigRaw, ok := data.GetOk("cluster"); ok && !validationUtils.IsEmpty(clusterConfigRaw) {
-		clusterConfigList := clusterConfigRaw.([]interface{})
+	if clusterConfigRaw, ok := data.GetOk("cluster"); ok && clusterConfigRaw.(*schema.Set).Len() > 0 {
+		clusterConfigList := clusterConfigRaw.(*schema.Set).List()
 		result := new(models.ComputeSpec)
 		var clusterSpecs []*models.ClusterSpec
 		for _, clusterConfigListEntry := range clusterConfigList {
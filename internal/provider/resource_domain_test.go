@@ -9,11 +9,14 @@ import (
 	"context"
 	"fmt"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 	"github.com/vmware/terraform-provider-vcf/internal/constants"
+	"github.com/vmware/terraform-provider-vcf/internal/resource_utils"
 	"github.com/vmware/vcf-sdk-go/client/domains"
 	"log"
 	"os"
+	"strings"
 	"testing"
 )
 
@@ -122,6 +125,40 @@ func TestAccResourceVcfDomain(t *testing.T) {
 					resource.TestCheckResourceAttrSet("vcf_domain.domain1", "cluster.1.host.2.id"),
 				),
 			},
+			{
+				// changing the order of the clusters in the configuration produces no diff
+				Config: testAccVcfDomainConfig(
+					testGenerateCommissionHostConfigs(
+						6,
+						os.Getenv(constants.VcfTestHost2Fqdn),
+						os.Getenv(constants.VcfTestHost2Pass),
+						os.Getenv(constants.VcfTestHost3Fqdn),
+						os.Getenv(constants.VcfTestHost3Pass),
+						os.Getenv(constants.VcfTestHost4Fqdn),
+						os.Getenv(constants.VcfTestHost4Pass),
+						os.Getenv(constants.VcfTestHost5Fqdn),
+						os.Getenv(constants.VcfTestHost5Pass),
+						os.Getenv(constants.VcfTestHost6Fqdn),
+						os.Getenv(constants.VcfTestHost6Pass),
+						os.Getenv(constants.VcfTestHost7Fqdn),
+						os.Getenv(constants.VcfTestHost7Pass)),
+					os.Getenv(constants.VcfTestNsxLicenseKey),
+					testAccVcfClusterInDomainConfig(
+						"sfo-w01-cl02",
+						testGenerateHostsInClusterInDomainConfig(
+							os.Getenv(constants.VcfTestEsxiLicenseKey),
+							"sfo-w01-cl02",
+							"host4", "host5", "host6"),
+						os.Getenv(constants.VcfTestVsanLicenseKey)),
+					testAccVcfClusterInDomainConfig(
+						"sfo-w01-cl01",
+						testGenerateHostsInClusterInDomainConfig(
+							os.Getenv(constants.VcfTestEsxiLicenseKey),
+							"sfo-w01-cl01",
+							"host1", "host2", "host3"),
+						os.Getenv(constants.VcfTestVsanLicenseKey))),
+				PlanOnly: true,
+			},
 			{
 				// add additional host in the second cluster in the domain
 				Config: testAccVcfDomainConfig(
@@ -336,6 +373,7 @@ func testAccVcfDomainConfig(commissionHostConfig, nsxLicenseKey,
 
 	resource "vcf_domain" "domain1" {
 		name                    = "sfo-w01-vc01"
+		deletion_protection     = false
 		vcenter {
 			name            = "test-vcenter"
 			datacenter_name = "test-datacenter"
@@ -432,6 +470,7 @@ func testGenerateCommissionHostConfigs(numberOfCommissionedHosts int, commission
 				password  = %q
 				network_pool_id = vcf_network_pool.domain_pool.id
 				storage_type = "VSAN"
+				deletion_protection = false
 		}
 		`, i+1, commissionHostsCredentials[i*2], commissionHostsCredentials[i*2+1])
 	}
@@ -467,3 +506,175 @@ func testCheckVcfDomainDestroy(state *terraform.State) error {
 	// Did not find the domain
 	return nil
 }
+
+func TestHandleDomainCreationFailureWithRetry(t *testing.T) {
+	data := schema.TestResourceDataRaw(t, ResourceDomain().Schema, map[string]interface{}{
+		"name":       "sfo-w01",
+		"on_failure": domainOnFailureRetry,
+	})
+
+	// the failed task has already been retried, the domain is neither looked up nor stored
+	diags := handleDomainCreationFailure(context.Background(), data, "task-1", domainOnFailureRetry,
+		fmt.Errorf("task failed"), nil)
+	if !diags.HasError() || diags[0].Summary != "task failed" {
+		t.Errorf("expected the error of the failed task, got %v", diags)
+	}
+	if len(data.Id()) > 0 {
+		t.Errorf("expected the domain not to be stored in the state, got ID %q", data.Id())
+	}
+}
+
+func TestCreateDomainUpdateSpecForDeletion(t *testing.T) {
+	domainUpdateSpec := createDomainUpdateSpec(nil, true)
+	if !domainUpdateSpec.MarkForDeletion {
+		t.Errorf("expected the domain to be marked for deletion")
+	}
+	if len(domainUpdateSpec.Name) > 0 {
+		t.Errorf("expected the domain not to be renamed, got %q", domainUpdateSpec.Name)
+	}
+}
+
+func TestValidateClusterUpdatesInDomain(t *testing.T) {
+	oldCluster := map[string]interface{}{
+		"id":             "cluster-1",
+		"name":           "sfo-w01-cl01",
+		"evc_mode":       "INTEL_SKYLAKE",
+		"geneve_vlan_id": 0,
+		"host":           []interface{}{map[string]interface{}{"id": "host-1"}},
+		"nfs_datastores": []interface{}{map[string]interface{}{"datastore_name": "nfs-ds-1", "path": "/nfs/ds1",
+			"read_only": false, "server_name": "nfs.vrack.vsphere.local", "user_tag": ""}},
+		"primary_datastore_name": "nfs-ds-1",
+	}
+	var clusterUpdateTests = []struct {
+		name        string
+		newCluster  map[string]interface{}
+		expectedErr string
+	}{
+		{"unchanged cluster", map[string]interface{}{}, ""},
+		{"changed hosts", map[string]interface{}{
+			"host": []interface{}{map[string]interface{}{"id": "host-2"}}}, ""},
+		{"added NFS datastore", map[string]interface{}{
+			"nfs_datastores": append([]interface{}{map[string]interface{}{"datastore_name": "nfs-ds-2", "path": "/nfs/ds2",
+				"read_only": true, "server_name": "nfs.vrack.vsphere.local", "user_tag": ""}},
+				oldCluster["nfs_datastores"].([]interface{})...)}, ""},
+		{"modified NFS datastore", map[string]interface{}{
+			"nfs_datastores": []interface{}{map[string]interface{}{"datastore_name": "nfs-ds-1", "path": "/nfs/other",
+				"read_only": false, "server_name": "nfs.vrack.vsphere.local", "user_tag": ""}}},
+			"modifying path of the mounted datastore \"nfs-ds-1\""},
+		{"removed primary datastore", map[string]interface{}{"nfs_datastores": []interface{}{}},
+			"removing the principal datastore \"nfs-ds-1\""},
+		{"computed attribute", map[string]interface{}{"id": ""}, ""},
+		{"changed EVC mode", map[string]interface{}{"evc_mode": "INTEL_CASCADELAKE"}, "\"evc_mode\""},
+		{"changed Geneve VLAN ID", map[string]interface{}{"geneve_vlan_id": 10}, "\"geneve_vlan_id\""},
+		{"added cluster", map[string]interface{}{"name": "sfo-w01-cl02", "evc_mode": ""}, ""},
+	}
+
+	for _, clusterUpdateTest := range clusterUpdateTests {
+		newCluster := make(map[string]interface{})
+		for attributeName, attributeValue := range oldCluster {
+			newCluster[attributeName] = attributeValue
+		}
+		for attributeName, attributeValue := range clusterUpdateTest.newCluster {
+			newCluster[attributeName] = attributeValue
+		}
+		err := validateClusterUpdatesInDomain([]interface{}{oldCluster}, []interface{}{newCluster})
+		if len(clusterUpdateTest.expectedErr) == 0 {
+			if err != nil {
+				t.Errorf("%s: unexpected error: %s", clusterUpdateTest.name, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), clusterUpdateTest.expectedErr) {
+			t.Errorf("%s: expected an error about %s, got %v", clusterUpdateTest.name,
+				clusterUpdateTest.expectedErr, err)
+		}
+	}
+}
+
+func TestHashDomainCluster(t *testing.T) {
+	clusterInConfig := map[string]interface{}{
+		"name":     "sfo-w01-cl01",
+		"evc_mode": "INTEL_SKYLAKE",
+		"host": []interface{}{
+			map[string]interface{}{"fqdn": "esxi-1.vrack.vsphere.local", "password": "VMware123!"},
+			map[string]interface{}{"id": "host-2"},
+		},
+	}
+	clusterInState := map[string]interface{}{
+		"id":                     "cluster-1",
+		"name":                   "sfo-w01-cl01",
+		"evc_mode":               "INTEL_SKYLAKE",
+		"primary_datastore_name": "sfo-w01-cl01-ds-vsan01",
+		"host": []interface{}{
+			map[string]interface{}{"id": "host-1", "fqdn": "esxi-1.vrack.vsphere.local",
+				"password": resource_utils.HashPassword("VMware123!")},
+			map[string]interface{}{"id": "host-2"},
+		},
+	}
+	if hashDomainCluster(clusterInConfig) != hashDomainCluster(clusterInState) {
+		t.Errorf("expected the cluster in the state to match the cluster in the configuration")
+	}
+
+	modifiedCluster := map[string]interface{}{
+		"name":     "sfo-w01-cl01",
+		"evc_mode": "INTEL_SKYLAKE",
+		"host": []interface{}{
+			map[string]interface{}{"fqdn": "esxi-1.vrack.vsphere.local"},
+			map[string]interface{}{"id": "host-2"},
+			map[string]interface{}{"id": "host-3"},
+		},
+	}
+	if hashDomainCluster(clusterInConfig) == hashDomainCluster(modifiedCluster) {
+		t.Errorf("expected adding a host to change the hash of the cluster, so that the change produces a diff")
+	}
+}
+
+func TestResourceDomainStateUpgradeV0(t *testing.T) {
+	rawState := map[string]interface{}{
+		"name": "sfo-w01",
+		"cluster": []interface{}{
+			map[string]interface{}{"name": "sfo-w01-cl01"},
+			map[string]interface{}{"name": "sfo-w01-cl02"},
+			map[string]interface{}{"name": "sfo-w01-cl01"},
+		},
+	}
+
+	upgradedState, err := resourceDomainStateUpgradeV0(context.Background(), rawState, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	clusters := upgradedState["cluster"].([]interface{})
+	if len(clusters) != 2 {
+		t.Fatalf("expected 2 clusters after the upgrade, got %d", len(clusters))
+	}
+	if upgradedState["name"] != "sfo-w01" {
+		t.Fatalf("domain name was not preserved by the upgrade")
+	}
+}
+
+func TestResolveHostIdsInClustersKeepsPinnedThumbprints(t *testing.T) {
+	testCluster := func(sshThumbprint string) map[string]interface{} {
+		return map[string]interface{}{
+			"id":   "cluster-1",
+			"name": "sfo-w01-cl01",
+			"host": []interface{}{map[string]interface{}{
+				"id":                      "host-1",
+				"fqdn":                    "esxi-1.vrack.invalid",
+				"ssh_thumbprint":          sshThumbprint,
+				"discover_ssh_thumbprint": true,
+			}},
+		}
+	}
+	newCluster := testCluster("")
+
+	// the host of the unchanged cluster is not connected, which would fail for the invalid FQDN
+	err := resolveHostIdsInClusters(context.Background(), []interface{}{newCluster},
+		[]interface{}{testCluster("SHA256:bmV0d29yay1wb29sLTEgdGh1bWJwcmludA")}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	host := newCluster["host"].([]interface{})[0].(map[string]interface{})
+	if host["ssh_thumbprint"] != "SHA256:bmV0d29yay1wb29sLTEgdGh1bWJwcmludA" {
+		t.Errorf("expected the pinned thumbprint to be kept, got %q", host["ssh_thumbprint"])
+	}
+}
@@ -6,20 +6,28 @@ package provider
 import (
 	"context"
 	"fmt"
+	"github.com/hashicorp/go-cty/cty"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/vmware/terraform-provider-vcf/internal/cluster"
 	"github.com/vmware/terraform-provider-vcf/internal/constants"
+	"github.com/vmware/terraform-provider-vcf/internal/network"
 	"github.com/vmware/terraform-provider-vcf/internal/resource_utils"
+	"github.com/vmware/vcf-sdk-go/client"
 	"github.com/vmware/vcf-sdk-go/client/credentials"
 	"github.com/vmware/vcf-sdk-go/client/hosts"
 	"github.com/vmware/vcf-sdk-go/models"
 
-	"log"
 	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
+// hostReleasePollInterval the delay between two requests for the status of a host, that is
+// waited for to be released from its cluster before it is decommissioned.
+const hostReleasePollInterval = 30 * time.Second
+
 func ResourceHost() *schema.Resource {
 	return &schema.Resource{
 		CreateContext: resourceHostCreate,
@@ -27,26 +35,32 @@ func ResourceHost() *schema.Resource {
 		UpdateContext: resourceHostUpdate,
 		DeleteContext: resourceHostDelete,
 		Importer: &schema.ResourceImporter{
-			StateContext: schema.ImportStatePassthroughContext,
+			StateContext: resourceHostImport,
 		},
+		CustomizeDiff: resourceHostCustomizeDiff,
 		Timeouts: &schema.ResourceTimeout{
 			Create: schema.DefaultTimeout(12 * time.Hour),
+			Update: schema.DefaultTimeout(12 * time.Hour),
+			Delete: schema.DefaultTimeout(12 * time.Hour),
 		},
 		Schema: map[string]*schema.Schema{
 			"fqdn": {
 				Type:        schema.TypeString,
 				Required:    true,
+				ForceNew:    true,
 				Description: "Fully qualified domain name of ESXi host",
 			},
 			"network_pool_id": {
-				Type:        schema.TypeString,
-				Required:    true,
-				Description: "ID of the network pool to associate the ESXi host with",
+				Type:     schema.TypeString,
+				Required: true,
+				Description: "ID of the network pool to associate the ESXi host with. Can only be changed while " +
+					"the host is not assigned to a cluster",
 			},
 			"storage_type": {
-				Type:        schema.TypeString,
-				Required:    true,
-				Description: "Storage Type. One among: VSAN, VSAN_REMOTE, NFS, VMFS_FC, VVOL",
+				Type:     schema.TypeString,
+				Required: true,
+				Description: "Storage Type. One among: VSAN, VSAN_REMOTE, NFS, VMFS_FC, VVOL. Can only be changed " +
+					"while the host is not assigned to a cluster",
 			},
 			"username": {
 				Type:        schema.TypeString,
@@ -54,10 +68,64 @@ func ResourceHost() *schema.Resource {
 				Description: "Username to authenticate to the ESXi host",
 			},
 			"password": {
-				Type:        schema.TypeString,
-				Required:    true,
-				Sensitive:   true,
-				Description: "Password to authenticate to the ESXi host",
+				Type:      schema.TypeString,
+				Required:  true,
+				Sensitive: true,
+				Description: "Password to authenticate to the ESXi host. Changing it updates the password " +
+					"of the host through SDDC Manager. The state holds a salted hash of it, which is compared with " +
+					"the password known to SDDC Manager to detect drift",
+				StateFunc:        resource_utils.HashPasswordStateFunc,
+				DiffSuppressFunc: suppressHostPasswordDiff,
+			},
+			"password_version": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Description: "Handles the password as write-only if set. Nothing but this version marker is kept in " +
+					"the state, changes of the password are ignored and the password of the host is updated only " +
+					"when the version marker changes. Password drift is not detected in this mode",
+				ValidateFunc: validation.NoZeroValues,
+			},
+			"deletion_protection": resource_utils.DeletionProtectionSchema(),
+			"ssh_thumbprint": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				Description: "SSH thumbprint of the ESXi host, verified by SDDC Manager when the host is commissioned. " +
+					"Computed if discover_thumbprints is set and the thumbprint is not configured. Set to an empty " +
+					"string to discover and pin the thumbprint again, e.g. after the certificate of the host is renewed",
+			},
+			"ssl_thumbprint": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				Description: "SSL thumbprint of the ESXi host, verified by SDDC Manager when the host is commissioned. " +
+					"Computed if discover_thumbprints is set and the thumbprint is not configured. Set to an empty " +
+					"string to discover and pin the thumbprint again, e.g. after the certificate of the host is renewed",
+			},
+			"discover_thumbprints": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+				Description: "Discovers the SSH and SSL thumbprints of the ESXi host during plan and pins them in the " +
+					"state (trust on first use). The pinned thumbprints are verified on every plan, a mismatch, e.g. " +
+					"because the host key changed, is reported as an error. See verify_thumbprints",
+			},
+			"verify_thumbprints": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+				Description: "Connects to the host on every plan to verify the pinned thumbprints if discover_thumbprints " +
+					"is set. If false, the host is connected only when the resource is created, the FQDN, " +
+					"discover_thumbprints or a configured thumbprint changes, or a thumbprint is cleared to pin it " +
+					"again, so a changed host key is not reported otherwise",
+			},
+			"wait_for_release": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+				Description: "If the host is assigned to a cluster when the resource is deleted, waits until the host " +
+					"is released from the cluster, e.g. by a cluster update in the same apply, instead of failing. " +
+					"The wait is limited by the delete timeout",
 			},
 			"status": {
 				Type:        schema.TypeString,
@@ -70,60 +138,33 @@ func ResourceHost() *schema.Resource {
 
 func resourceHostCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	vcfClient := meta.(*SddcManagerClient)
-	apiClient := vcfClient.ApiClient
-	params := hosts.NewCommissionHostsParamsWithTimeout(constants.DefaultVcfApiCallTimeout)
-	commissionSpec := models.HostCommissionSpec{}
-
-	if fqdn, ok := d.GetOk("fqdn"); ok {
-		fqdnVal := fqdn.(string)
-		commissionSpec.Fqdn = &fqdnVal
-	}
-
-	if storageType, ok := d.GetOk("storage_type"); ok {
-		storageTypeVal := storageType.(string)
-		commissionSpec.StorageType = &storageTypeVal
-	}
+	commissionSpec := tryConvertResourceDataToHostCommissionSpec(d)
 
-	if username, ok := d.GetOk("username"); ok {
-		usernameVal := username.(string)
-		commissionSpec.Username = &usernameVal
-	}
-
-	if password, ok := d.GetOk("password"); ok {
-		passwordVal := password.(string)
-		commissionSpec.Password = &passwordVal
-	}
-
-	if networkPoolId, ok := d.GetOk("network_pool_id"); ok {
-		networkPoolIdStr := networkPoolId.(string)
-		commissionSpec.NetworkPoolID = &networkPoolIdStr
-	}
-
-	params.HostCommissionSpecs = []*models.HostCommissionSpec{&commissionSpec}
-
-	_, accepted, err := apiClient.Hosts.CommissionHosts(params)
-	if err != nil {
-		tflog.Error(ctx, err.Error())
-		return diag.FromErr(err)
-	}
-	taskId := accepted.Payload.ID
-
-	tflog.Info(ctx, fmt.Sprintf("%s commissionSpec commission initiated. waiting for task id = %s",
-		*commissionSpec.Fqdn, taskId))
-
-	err = vcfClient.WaitForTaskComplete(ctx, taskId, false)
-	if err != nil {
-		tflog.Error(ctx, err.Error())
-		return diag.FromErr(err)
-	}
-	hostId, err := vcfClient.GetResourceIdAssociatedWithTask(ctx, taskId, "Esxi")
-	if err != nil {
-		return diag.FromErr(err)
+	// the commission is validated first, so that e.g. wrong credentials are reported before any task is started
+	hostIdsByFqdn, diags := commissionHosts(ctx, []*models.HostCommissionSpec{commissionSpec}, vcfClient)
+	if diags.HasError() {
+		return diags
 	}
+	hostId := hostIdsByFqdn[*commissionSpec.Fqdn]
 
 	d.SetId(hostId)
 
-	return resourceHostRead(ctx, d, meta)
+	return append(diags, resourceHostRead(ctx, d, meta)...)
+}
+
+func tryConvertResourceDataToHostCommissionSpec(d *schema.ResourceData) *models.HostCommissionSpec {
+	commissionSpec := tryConvertToHostCommissionSpecs([]interface{}{
+		map[string]interface{}{
+			"fqdn":            d.Get("fqdn"),
+			"network_pool_id": d.Get("network_pool_id"),
+			"storage_type":    d.Get("storage_type"),
+			"username":        d.Get("username"),
+			"password":        getConfiguredHostPassword(d),
+		},
+	})[0]
+	commissionSpec.SSHThumbprint = d.Get("ssh_thumbprint").(string)
+	commissionSpec.SSLThumbprint = d.Get("ssl_thumbprint").(string)
+	return commissionSpec
 }
 
 func resourceHostRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
@@ -165,36 +206,299 @@ func resourceHostRead(ctx context.Context, d *schema.ResourceData, meta interfac
 			return diag.FromErr(fmt.Errorf("hostId doesn't match host FQDN when requesting credentials"))
 		}
 		_ = d.Set("username", *credential.Username)
-		_ = d.Set("password", credential.Password)
+		setHostPasswordData(d, credential.Password)
 	}
 
 	return nil
 }
 
-// There is no update method for commissioned hosts.
+// resourceHostUpdate updates the password of the host through the credentials API. A host whose
+// network pool or storage type changes is decommissioned and commissioned again, which is only
+// possible while it is not assigned to a cluster.
 func resourceHostUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	return resourceDomainRead(ctx, d, meta)
+	vcfClient := meta.(*SddcManagerClient)
+	fqdn := d.Get("fqdn").(string)
+
+	if d.HasChanges("password", "password_version") {
+		diags := updateHostPassword(ctx, fqdn, d.Get("username").(string), getConfiguredHostPassword(d), vcfClient)
+		if diags != nil {
+			return diags
+		}
+	}
+
+	var diags diag.Diagnostics
+	if hasHostCommissionChange(d.GetChange) {
+		// the status is checked again, as it may have changed since the plan
+		getHostParams := hosts.NewGetHostParamsWithContext(ctx).WithTimeout(constants.DefaultVcfApiCallTimeout)
+		getHostParams.ID = d.Id()
+		hostResponse, err := vcfClient.ApiClient.Hosts.GetHost(getHostParams)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		if err = validateHostIsUnassigned(hostResponse.Payload.Status, fqdn); err != nil {
+			return diag.FromErr(err)
+		}
+
+		tflog.Info(ctx, fmt.Sprintf("Recommissioning host %q with a new network pool or storage type", fqdn))
+		diags = decommissionHosts(ctx, []string{fqdn}, vcfClient)
+		if diags.HasError() {
+			return diags
+		}
+		commissionSpec := tryConvertResourceDataToHostCommissionSpec(d)
+		var hostIdsByFqdn map[string]string
+		hostIdsByFqdn, diags = commissionHosts(ctx, []*models.HostCommissionSpec{commissionSpec}, vcfClient)
+		if diags.HasError() {
+			// the host is decommissioned, it is commissioned again by the next apply
+			d.SetId("")
+			return diags
+		}
+		d.SetId(hostIdsByFqdn[fqdn])
+	}
+
+	return append(diags, resourceHostRead(ctx, d, meta)...)
+}
+
+// resourceHostCustomizeDiff pins the thumbprints of the host and rejects the changes that can't be
+// applied to a commissioned host.
+func resourceHostCustomizeDiff(ctx context.Context, diff *schema.ResourceDiff, _ interface{}) error {
+	if diff.Get("discover_thumbprints").(bool) && needsThumbprintDiscovery(diff) {
+		if err := pinHostThumbprints(ctx, diff); err != nil {
+			return err
+		}
+	}
+	if len(diff.Id()) == 0 {
+		return nil
+	}
+	if diff.HasChange("username") {
+		return fmt.Errorf("changing the username of the commissioned host %q is not supported", diff.Get("fqdn"))
+	}
+	if hasHostCommissionChange(diff.GetChange) {
+		status, _ := diff.Get("status").(string)
+		return validateHostIsUnassigned(status, diff.Get("fqdn").(string))
+	}
+	return nil
+}
+
+// hostThumbprintAttributes the names of the pinned thumbprint attributes of a host.
+var hostThumbprintAttributes = []string{"ssh_thumbprint", "ssl_thumbprint"}
+
+// needsThumbprintDiscovery returns whether the host has to be connected to discover its thumbprints,
+// i.e. if the host is new or replaced, the discovery is turned on, or a thumbprint is missing in the
+// state, changed in the configuration or cleared to pin it again.
+func needsThumbprintDiscovery(diff *schema.ResourceDiff) bool {
+	if len(diff.Id()) == 0 || diff.Get("verify_thumbprints").(bool) ||
+		diff.HasChanges("fqdn", "discover_thumbprints") {
+		return true
+	}
+	rawConfig := diff.GetRawConfig()
+	for _, attributeName := range hostThumbprintAttributes {
+		stateThumbprint, _ := diff.GetChange(attributeName)
+		if len(stateThumbprint.(string)) == 0 || diff.HasChange(attributeName) ||
+			isThumbprintClearedInConfig(rawConfig, attributeName) {
+			return true
+		}
+	}
+	return false
+}
+
+// isThumbprintClearedInConfig returns whether the thumbprint is set to an empty string in the configuration,
+// which requests to discover and pin the thumbprint again. The diff holds the state value in this case.
+func isThumbprintClearedInConfig(rawConfig cty.Value, attributeName string) bool {
+	if rawConfig.IsNull() || !rawConfig.IsKnown() {
+		return false
+	}
+	configValue := rawConfig.GetAttr(attributeName)
+	return !configValue.IsNull() && configValue.IsKnown() && len(configValue.AsString()) == 0
+}
+
+// pinHostThumbprints discovers the SSH and SSL thumbprints of the host. A discovered thumbprint is
+// pinned if none is configured or stored in the state for the host, or if the thumbprint is cleared
+// in the configuration, otherwise it has to match the pinned thumbprint. Hosts whose FQDN is not
+// known yet are pinned on the next plan.
+func pinHostThumbprints(ctx context.Context, diff *schema.ResourceDiff) error {
+	fqdn, _ := diff.Get("fqdn").(string)
+	if len(fqdn) == 0 {
+		return nil
+	}
+	rawConfig := diff.GetRawConfig()
+	for _, thumbprint := range []struct {
+		attributeName  string
+		thumbprintType string
+		discover       func(ctx context.Context, address string) (string, error)
+	}{
+		{"ssh_thumbprint", "SSH", network.GetSshThumbprint},
+		{"ssl_thumbprint", "SSL", network.GetSslThumbprint},
+	} {
+		discoveredThumbprint, err := thumbprint.discover(ctx, fqdn)
+		if err != nil {
+			return err
+		}
+
+		var pinnedThumbprint string
+		switch {
+		case isThumbprintClearedInConfig(rawConfig, thumbprint.attributeName):
+			// the discovered thumbprint is pinned again
+		case !rawConfig.IsNull() && rawConfig.IsKnown() && !rawConfig.GetAttr(thumbprint.attributeName).IsNull():
+			pinnedThumbprint, _ = diff.Get(thumbprint.attributeName).(string)
+		case !diff.HasChange("fqdn"):
+			// the thumbprint pinned for a replaced host is not kept
+			stateThumbprint, _ := diff.GetChange(thumbprint.attributeName)
+			pinnedThumbprint, _ = stateThumbprint.(string)
+		}
+
+		if len(pinnedThumbprint) == 0 {
+			if err = diff.SetNew(thumbprint.attributeName, discoveredThumbprint); err != nil {
+				return err
+			}
+			continue
+		}
+		err = network.VerifyPinnedThumbprint(thumbprint.thumbprintType, fqdn, pinnedThumbprint, discoveredThumbprint)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// hasHostCommissionChange returns whether the network pool or the storage type of the host change.
+// The API doesn't return the storage type, so a change from an empty value, e.g. after an import,
+// is only stored in the state.
+func hasHostCommissionChange(getChange func(key string) (interface{}, interface{})) bool {
+	oldNetworkPoolId, newNetworkPoolId := getChange("network_pool_id")
+	oldStorageType, newStorageType := getChange("storage_type")
+	return oldNetworkPoolId != newNetworkPoolId || (oldStorageType != "" && oldStorageType != newStorageType)
+}
+
+func validateHostIsUnassigned(status, fqdn string) error {
+	if status != cluster.HostStatusUnassignedUseable {
+		return fmt.Errorf("the network pool and storage type of host %q can only be changed while the host is "+
+			"not assigned to a cluster, the host is in status %q. Remove the host from its cluster or replace the "+
+			"vcf_host resource", fqdn, status)
+	}
+	return nil
+}
+
+// suppressHostPasswordDiff ignores the changes of a write-only password, which is updated only
+// when its version marker changes, and the passwords that match the hash in the state.
+func suppressHostPasswordDiff(key, oldValue, newValue string, d *schema.ResourceData) bool {
+	if len(d.Id()) > 0 && len(d.Get("password_version").(string)) > 0 {
+		return true
+	}
+	return resource_utils.SuppressMatchingPasswordDiff(key, oldValue, newValue, d)
+}
+
+// setHostPasswordData sets the hash of the password known to SDDC Manager, unless the password in
+// the state matches it already, so that a password changed outside of Terraform produces a diff.
+// In write-only mode the password is removed from the state.
+func setHostPasswordData(d *schema.ResourceData, currentPassword string) {
+	if len(d.Get("password_version").(string)) > 0 {
+		_ = d.Set("password", "")
+		return
+	}
+	statePassword := d.Get("password").(string)
+	if resource_utils.IsPasswordHash(statePassword) &&
+		resource_utils.PasswordMatchesHash(currentPassword, statePassword) {
+		return
+	}
+	_ = d.Set("password", resource_utils.HashPassword(currentPassword))
+}
+
+// getConfiguredHostPassword returns the password in the configuration, as the state holds only its hash
+// and the diff of a write-only password is suppressed.
+func getConfiguredHostPassword(d *schema.ResourceData) string {
+	rawConfig := d.GetRawConfig()
+	if rawConfig.IsNull() || !rawConfig.IsKnown() {
+		return d.Get("password").(string)
+	}
+	password := rawConfig.GetAttr("password")
+	if password.IsNull() || !password.IsKnown() {
+		return d.Get("password").(string)
+	}
+	return password.AsString()
+}
+
+// updateHostPassword updates the password of the SSH user of the host, known to SDDC Manager.
+func updateHostPassword(ctx context.Context, fqdn, username, password string, vcfClient *SddcManagerClient) diag.Diagnostics {
+	updatePasswordsParams := credentials.NewUpdateOrRotatePasswordsParamsWithContext(ctx).
+		WithTimeout(constants.DefaultVcfApiCallTimeout)
+	updatePasswordsParams.CredentialsUpdateSpec = &models.CredentialsUpdateSpec{
+		OperationType: resource_utils.ToStringPointer("UPDATE"),
+		Elements: []*models.ResourceCredentials{
+			{
+				ResourceName: fqdn,
+				ResourceType: resource_utils.ToStringPointer("ESXI"),
+				Credentials: []*models.BaseCredential{
+					{
+						CredentialType: "SSH",
+						Username:       &username,
+						Password:       password,
+					},
+				},
+			},
+		},
+	}
+
+	okResponse, acceptedResponse, err := vcfClient.ApiClient.Credentials.UpdateOrRotatePasswords(updatePasswordsParams)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	var taskId string
+	if okResponse != nil {
+		taskId = okResponse.Payload.ID
+	}
+	if acceptedResponse != nil {
+		taskId = acceptedResponse.Payload.ID
+	}
+	tflog.Info(ctx, fmt.Sprintf("Password update of host %q initiated, waiting for task id = %s", fqdn, taskId))
+	err = vcfClient.WaitForCredentialsTaskComplete(ctx, taskId)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	return nil
+}
+
+// resourceHostImport imports a host by its ID or by its FQDN.
+func resourceHostImport(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	if _, errs := validation.IsUUID(d.Id(), "id"); len(errs) > 0 {
+		vcfClient := meta.(*SddcManagerClient)
+		hostObj, err := getHostByFqdn(ctx, d.Id(), vcfClient.ApiClient)
+		if err != nil {
+			return nil, err
+		}
+		d.SetId(hostObj.ID)
+	}
+	_ = d.Set("deletion_protection", true)
+	return []*schema.ResourceData{d}, nil
 }
 
 func resourceHostDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	vcfClient := meta.(*SddcManagerClient)
 	apiClient := vcfClient.ApiClient
 
+	err := resource_utils.CheckDeletionProtection(d.Get("deletion_protection"), "host", d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	// a host assigned to a cluster can't be decommissioned, which SDDC Manager reports only after starting the task
+	err = waitForHostRelease(ctx, d.Id(), d.Get("fqdn").(string), d.Get("wait_for_release").(bool), apiClient)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
 	params := hosts.NewDecommissionHostsParamsWithTimeout(constants.DefaultVcfApiCallTimeout)
 	decommissionSpec := models.HostDecommissionSpec{}
 	decommissionSpec.Fqdn = resource_utils.ToStringPointer(d.Get("fqdn"))
 	params.HostDecommissionSpecs = []*models.HostDecommissionSpec{&decommissionSpec}
 
-	log.Println(params)
-
 	_, accepted, err := apiClient.Hosts.DecommissionHosts(params)
 	if err != nil {
 		tflog.Error(ctx, err.Error())
 		return diag.FromErr(err)
 	}
 
-	log.Printf("%s %s: Decommission task initiated. Task id %s",
-		d.Get("fqdn").(string), d.Id(), accepted.Payload.ID)
+	tflog.Info(ctx, fmt.Sprintf("%s %s: Decommission task initiated. Task id %s",
+		d.Get("fqdn").(string), d.Id(), accepted.Payload.ID))
 	err = vcfClient.WaitForTaskComplete(ctx, accepted.Payload.ID, false)
 	if err != nil {
 		tflog.Error(ctx, err.Error())
@@ -203,3 +507,40 @@ func resourceHostDelete(ctx context.Context, d *schema.ResourceData, meta interf
 
 	return nil
 }
+
+// waitForHostRelease returns an error with guidance if the host is assigned to a cluster, unless
+// waitForRelease is set. In this case the host is polled until it is released or the context expires.
+func waitForHostRelease(ctx context.Context, hostId, fqdn string, waitForRelease bool, apiClient *client.VcfClient) error {
+	for {
+		hostObj, err := getHost(ctx, hostId, apiClient)
+		if err != nil {
+			return err
+		}
+		if hostObj.Status != cluster.HostStatusAssigned {
+			return nil
+		}
+		if !waitForRelease {
+			return fmt.Errorf("host %q is assigned to %s and can't be decommissioned. Remove the host from its "+
+				"cluster first, or set wait_for_release to wait until it is removed, e.g. by a cluster update in "+
+				"the same apply", fqdn, describeHostAssignment(hostObj))
+		}
+
+		tflog.Info(ctx, fmt.Sprintf("Waiting for host %q to be released from %s", fqdn, describeHostAssignment(hostObj)))
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("timed out waiting for host %q to be released from %s", fqdn, describeHostAssignment(hostObj))
+		case <-time.After(hostReleasePollInterval):
+		}
+	}
+}
+
+func describeHostAssignment(hostObj *models.Host) string {
+	result := "a cluster"
+	if hostObj.Cluster != nil && hostObj.Cluster.ID != nil {
+		result = fmt.Sprintf("cluster %q", *hostObj.Cluster.ID)
+	}
+	if hostObj.Domain != nil && len(hostObj.Domain.Name) > 0 {
+		result += fmt.Sprintf(" in domain %q", hostObj.Domain.Name)
+	}
+	return result
+}
@@ -4,12 +4,17 @@
 package provider
 
 import (
+	"context"
 	"fmt"
+	"github.com/hashicorp/go-cty/cty"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 	"github.com/vmware/terraform-provider-vcf/internal/constants"
+	"github.com/vmware/terraform-provider-vcf/internal/resource_utils"
 	"log"
 	"os"
+	"strings"
 	"testing"
 )
 
@@ -25,20 +30,82 @@ func TestAccResourceVcfHost(t *testing.T) {
 					os.Getenv(constants.VcfTestHost1Pass)),
 				Check: resource.ComposeTestCheckFunc(
 					resource.TestCheckResourceAttrSet("vcf_host.host1", "id"),
+					resource.TestCheckResourceAttrWith("vcf_host.host1", "password", testCheckPasswordHashed),
 				),
 			},
 			{
 				ResourceName:      "vcf_host.host1",
 				ImportState:       true,
 				ImportStateVerify: true,
-				// The GetHost API returns empty string for "CompatibleStorageType"
-				ImportStateVerifyIgnore: []string{"storage_type"},
+				// The GetHost API returns empty string for "CompatibleStorageType",
+				// imported hosts are always protected from deletion
+				// and the password hashes have different salts
+				ImportStateVerifyIgnore: []string{"storage_type", "deletion_protection", "password", "wait_for_release",
+					"discover_thumbprints"},
+			},
+			{
+				ResourceName:      "vcf_host.host1",
+				ImportState:       true,
+				ImportStateIdFunc: testAccVcfHostImportStateIdByFqdn,
+				ImportStateVerify: true,
+				ImportStateVerifyIgnore: []string{"storage_type", "deletion_protection", "password", "wait_for_release",
+					"discover_thumbprints"},
+			},
+			{
+				Config: testAccVcfHostConfigWriteOnlyPassword(
+					os.Getenv(constants.VcfTestHost1Fqdn),
+					os.Getenv(constants.VcfTestHost1Pass)),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("vcf_host.host1", "password_version", "1"),
+					resource.TestCheckResourceAttr("vcf_host.host1", "password", ""),
+				),
+			},
+			{
+				Config: testAccVcfHostConfigDiscoverThumbprints(
+					os.Getenv(constants.VcfTestHost1Fqdn),
+					os.Getenv(constants.VcfTestHost1Pass)),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrWith("vcf_host.host1", "ssh_thumbprint", func(value string) error {
+						if !strings.HasPrefix(value, "SHA256:") {
+							return fmt.Errorf("unexpected SSH thumbprint %q", value)
+						}
+						return nil
+					}),
+					resource.TestCheckResourceAttrSet("vcf_host.host1", "ssl_thumbprint"),
+				),
 			},
 		},
 	})
 }
 
+func testAccVcfHostImportStateIdByFqdn(state *terraform.State) (string, error) {
+	host, ok := state.RootModule().Resources["vcf_host.host1"]
+	if !ok {
+		return "", fmt.Errorf("vcf_host.host1 not found in the state")
+	}
+	return host.Primary.Attributes["fqdn"], nil
+}
+
+func testCheckPasswordHashed(value string) error {
+	if !resource_utils.IsPasswordHash(value) {
+		return fmt.Errorf("the password is not hashed in the state")
+	}
+	return nil
+}
+
 func testAccVcfHostConfig(hostFqdn, hostSshPassword string) string {
+	return testAccVcfHostConfigWithAttributes(hostFqdn, hostSshPassword, "")
+}
+
+func testAccVcfHostConfigWriteOnlyPassword(hostFqdn, hostSshPassword string) string {
+	return testAccVcfHostConfigWithAttributes(hostFqdn, hostSshPassword, "password_version = \"1\"")
+}
+
+func testAccVcfHostConfigDiscoverThumbprints(hostFqdn, hostSshPassword string) string {
+	return testAccVcfHostConfigWithAttributes(hostFqdn, hostSshPassword, "discover_thumbprints = true")
+}
+
+func testAccVcfHostConfigWithAttributes(hostFqdn, hostSshPassword, attributes string) string {
 	return fmt.Sprintf(`
 	resource "vcf_network_pool" "eng_pool" {
 		name    = "engineering-pool"
@@ -74,7 +141,9 @@ func testAccVcfHostConfig(hostFqdn, hostSshPassword string) string {
 		password  = %q
 		network_pool_id = vcf_network_pool.eng_pool.id
 		storage_type = "VSAN"
-	}`, hostFqdn, hostSshPassword)
+		deletion_protection = false
+		%s
+	}`, hostFqdn, hostSshPassword, attributes)
 }
 
 func testCheckVcfHostDestroy(_ *terraform.State) error {
@@ -96,3 +165,170 @@ func testCheckVcfHostDestroy(_ *terraform.State) error {
 	// Found the host
 	return nil
 }
+
+func TestHasHostCommissionChange(t *testing.T) {
+	var changeTests = []struct {
+		name           string
+		oldValues      map[string]interface{}
+		newValues      map[string]interface{}
+		expectedChange bool
+	}{
+		{"unchanged",
+			map[string]interface{}{"network_pool_id": "network-pool-1", "storage_type": "VSAN"},
+			map[string]interface{}{"network_pool_id": "network-pool-1", "storage_type": "VSAN"}, false},
+		{"network pool",
+			map[string]interface{}{"network_pool_id": "network-pool-1", "storage_type": "VSAN"},
+			map[string]interface{}{"network_pool_id": "network-pool-2", "storage_type": "VSAN"}, true},
+		{"storage type",
+			map[string]interface{}{"network_pool_id": "network-pool-1", "storage_type": "VSAN"},
+			map[string]interface{}{"network_pool_id": "network-pool-1", "storage_type": "NFS"}, true},
+		{"storage type after import",
+			map[string]interface{}{"network_pool_id": "network-pool-1", "storage_type": ""},
+			map[string]interface{}{"network_pool_id": "network-pool-1", "storage_type": "NFS"}, false},
+	}
+	for _, changeTest := range changeTests {
+		getChange := func(key string) (interface{}, interface{}) {
+			return changeTest.oldValues[key], changeTest.newValues[key]
+		}
+		if hasHostCommissionChange(getChange) != changeTest.expectedChange {
+			t.Errorf("%s: expected change %t", changeTest.name, changeTest.expectedChange)
+		}
+	}
+}
+
+func TestValidateHostIsUnassigned(t *testing.T) {
+	err := validateHostIsUnassigned("UNASSIGNED_USEABLE", "esxi-1.vrack.vsphere.local")
+	if err != nil {
+		t.Errorf("unexpected error for an unassigned host %v", err)
+	}
+	for _, status := range []string{"ASSIGNED", "UNASSIGNED_UNUSEABLE"} {
+		err = validateHostIsUnassigned(status, "esxi-1.vrack.vsphere.local")
+		if err == nil || !strings.Contains(err.Error(), "can only be changed while the host is not assigned") ||
+			!strings.Contains(err.Error(), status) {
+			t.Errorf("%s: expected an error for a host that is not unassigned, got %v", status, err)
+		}
+	}
+}
+
+func TestResourceHostCustomizeDiff(t *testing.T) {
+	hostConfig := func(attributes ...string) map[string]interface{} {
+		result := map[string]interface{}{
+			"fqdn":            "esxi-1.vrack.vsphere.local",
+			"network_pool_id": "network-pool-1",
+			"storage_type":    "VSAN",
+			"username":        "root",
+			"password":        "VMware123!",
+		}
+		for i := 0; i+1 < len(attributes); i += 2 {
+			result[attributes[i]] = attributes[i+1]
+		}
+		return result
+	}
+
+	var diffTests = []struct {
+		name        string
+		newConfig   map[string]interface{}
+		expectedErr string
+	}{
+		{"unchanged", hostConfig(), ""},
+		{"password", hostConfig("password", "VMware1234!"), ""},
+		{"username", hostConfig("username", "admin"),
+			"changing the username of the commissioned host \"esxi-1.vrack.vsphere.local\" is not supported"},
+		// the status is computed and not known in the test, so the host is not unassigned
+		{"network pool", hostConfig("network_pool_id", "network-pool-2"),
+			"can only be changed while the host is not assigned to a cluster"},
+	}
+	for _, diffTest := range diffTests {
+		err := planResourceChange(t, ResourceHost(), "host-1", hostConfig(), diffTest.newConfig)
+		if len(diffTest.expectedErr) == 0 {
+			if err != nil {
+				t.Errorf("%s: unexpected error %v", diffTest.name, err)
+			}
+		} else if err == nil || !strings.Contains(err.Error(), diffTest.expectedErr) {
+			t.Errorf("%s: expected error containing %q, got %v", diffTest.name, diffTest.expectedErr, err)
+		}
+	}
+}
+
+func TestNeedsThumbprintDiscovery(t *testing.T) {
+	hostConfig := func(attributes map[string]interface{}) map[string]interface{} {
+		result := map[string]interface{}{
+			"fqdn":                 "esxi-1.vrack.vsphere.local",
+			"network_pool_id":      "network-pool-1",
+			"storage_type":         "VSAN",
+			"username":             "root",
+			"password":             "VMware123!",
+			"discover_thumbprints": true,
+			"verify_thumbprints":   false,
+			"ssh_thumbprint":       "SHA256:bmV0d29yay1wb29sLTEgdGh1bWJwcmludA",
+			"ssl_thumbprint":       "AB:CD:EF:01:23:45:67:89",
+		}
+		for attributeName, attributeValue := range attributes {
+			if attributeValue == nil {
+				delete(result, attributeName)
+				continue
+			}
+			result[attributeName] = attributeValue
+		}
+		return result
+	}
+
+	var discoveryTests = []struct {
+		name              string
+		oldConfig         map[string]interface{}
+		newConfig         map[string]interface{}
+		expectedDiscovery bool
+	}{
+		{"unchanged", hostConfig(nil), hostConfig(nil), false},
+		{"unchanged with verification", hostConfig(map[string]interface{}{"verify_thumbprints": nil}),
+			hostConfig(map[string]interface{}{"verify_thumbprints": nil}), true},
+		{"thumbprint removed from the configuration", hostConfig(nil),
+			hostConfig(map[string]interface{}{"ssh_thumbprint": nil, "ssl_thumbprint": nil}), false},
+		{"fqdn", hostConfig(nil), hostConfig(map[string]interface{}{"fqdn": "esxi-2.vrack.vsphere.local"}), true},
+		{"discovery turned on", hostConfig(map[string]interface{}{"discover_thumbprints": false}), hostConfig(nil), true},
+		{"thumbprint changed", hostConfig(nil),
+			hostConfig(map[string]interface{}{"ssl_thumbprint": "AB:CD:EF:01:23:45:67:90"}), true},
+		{"thumbprint cleared", hostConfig(nil), hostConfig(map[string]interface{}{"ssh_thumbprint": ""}), true},
+		{"thumbprint not pinned", hostConfig(map[string]interface{}{"ssh_thumbprint": nil}),
+			hostConfig(map[string]interface{}{"ssh_thumbprint": nil}), true},
+	}
+	for _, discoveryTest := range discoveryTests {
+		var discovery bool
+		hostResource := ResourceHost()
+		// the host is not connected in the test, only the decision is recorded
+		hostResource.CustomizeDiff = func(_ context.Context, diff *schema.ResourceDiff, _ interface{}) error {
+			discovery = needsThumbprintDiscovery(diff)
+			return nil
+		}
+		err := planResourceChange(t, hostResource, "host-1", discoveryTest.oldConfig, discoveryTest.newConfig)
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", discoveryTest.name, err)
+			continue
+		}
+		if discovery != discoveryTest.expectedDiscovery {
+			t.Errorf("%s: expected thumbprint discovery %t", discoveryTest.name, discoveryTest.expectedDiscovery)
+		}
+	}
+}
+
+func TestIsThumbprintClearedInConfig(t *testing.T) {
+	configValue := func(thumbprint cty.Value) cty.Value {
+		return cty.ObjectVal(map[string]cty.Value{"ssh_thumbprint": thumbprint})
+	}
+	var clearedTests = []struct {
+		name            string
+		rawConfig       cty.Value
+		expectedCleared bool
+	}{
+		{"cleared", configValue(cty.StringVal("")), true},
+		{"configured", configValue(cty.StringVal("SHA256:bmV0d29yay1wb29sLTEgdGh1bWJwcmludA")), false},
+		{"not configured", configValue(cty.NullVal(cty.String)), false},
+		{"unknown", configValue(cty.UnknownVal(cty.String)), false},
+		{"no configuration", cty.NullVal(cty.Object(map[string]cty.Type{"ssh_thumbprint": cty.String})), false},
+	}
+	for _, clearedTest := range clearedTests {
+		if isThumbprintClearedInConfig(clearedTest.rawConfig, "ssh_thumbprint") != clearedTest.expectedCleared {
+			t.Errorf("%s: expected cleared %t", clearedTest.name, clearedTest.expectedCleared)
+		}
+	}
+}
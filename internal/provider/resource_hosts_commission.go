@@ -0,0 +1,493 @@
+/* Copyright 2023 VMware, Inc.
+   SPDX-License-Identifier: MPL-2.0 */
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/vmware/terraform-provider-vcf/internal/cluster"
+	"github.com/vmware/terraform-provider-vcf/internal/constants"
+	"github.com/vmware/terraform-provider-vcf/internal/resource_utils"
+	validationUtils "github.com/vmware/terraform-provider-vcf/internal/validation"
+	"github.com/vmware/vcf-sdk-go/client/hosts"
+	"github.com/vmware/vcf-sdk-go/models"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// hostCommissionValidationPollInterval the delay between two requests for the status of a
+// host commission validation.
+const hostCommissionValidationPollInterval = 10 * time.Second
+
+// ResourceHostsCommission commissions a group of ESXi hosts with a single SDDC Manager workflow,
+// so that the hosts are validated and commissioned together instead of in competing tasks.
+func ResourceHostsCommission() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceHostsCommissionCreate,
+		ReadContext:   resourceHostsCommissionRead,
+		UpdateContext: resourceHostsCommissionUpdate,
+		DeleteContext: resourceHostsCommissionDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceHostsCommissionImport,
+		},
+		CustomizeDiff: validateCommissionedHostChangesInDiff,
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(12 * time.Hour),
+			Update: schema.DefaultTimeout(12 * time.Hour),
+			Delete: schema.DefaultTimeout(12 * time.Hour),
+		},
+		Schema: map[string]*schema.Schema{
+			"host": {
+				Type:     schema.TypeSet,
+				Required: true,
+				MinItems: 1,
+				Description: "ESXi hosts to commission. Hosts added to the set are commissioned together and " +
+					"hosts removed from it are decommissioned together. The attributes of a commissioned host can't be " +
+					"changed, remove the host and add it again instead",
+				Set: hashCommissionedHost,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"fqdn": {
+							Type:         schema.TypeString,
+							Required:     true,
+							Description:  "Fully qualified domain name of ESXi host",
+							ValidateFunc: validation.NoZeroValues,
+						},
+						"network_pool_id": {
+							Type:         schema.TypeString,
+							Required:     true,
+							Description:  "ID of the network pool to associate the ESXi host with",
+							ValidateFunc: validation.NoZeroValues,
+						},
+						"storage_type": {
+							Type:         schema.TypeString,
+							Required:     true,
+							Description:  "Storage Type. One among: VSAN, VSAN_REMOTE, NFS, VMFS_FC, VVOL",
+							ValidateFunc: validation.StringInSlice([]string{"VSAN", "VSAN_REMOTE", "NFS", "VMFS_FC", "VVOL"}, false),
+						},
+						"username": {
+							Type:         schema.TypeString,
+							Required:     true,
+							Description:  "Username to authenticate to the ESXi host",
+							ValidateFunc: validation.NoZeroValues,
+						},
+						"password": {
+							Type:             schema.TypeString,
+							Required:         true,
+							Sensitive:        true,
+							Description:      "Password to authenticate to the ESXi host. The state holds a salted hash of it",
+							ValidateFunc:     validation.NoZeroValues,
+							StateFunc:        resource_utils.HashPasswordStateFunc,
+							DiffSuppressFunc: resource_utils.SuppressMatchingPasswordDiff,
+						},
+						"id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "ID of the commissioned ESXi host",
+						},
+						"status": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Assignable status of the host",
+						},
+					},
+				},
+			},
+			"deletion_protection": resource_utils.DeletionProtectionSchema(),
+		},
+	}
+}
+
+func resourceHostsCommissionCreate(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	vcfClient := meta.(*SddcManagerClient)
+
+	hostsList := data.Get("host").(*schema.Set).List()
+	hostIdsByFqdn, diags := commissionHosts(ctx, tryConvertToHostCommissionSpecs(hostsList), vcfClient)
+	if diags.HasError() {
+		return diags
+	}
+	setCommissionedHostIds(hostsList, hostIdsByFqdn)
+	_ = data.Set("host", hostsList)
+
+	var hostIds []string
+	for _, hostId := range hostIdsByFqdn {
+		hostIds = append(hostIds, hostId)
+	}
+	data.SetId(hostsCommissionId(hostIds))
+
+	return append(diags, resourceHostsCommissionRead(ctx, data, meta)...)
+}
+
+func resourceHostsCommissionRead(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	vcfClient := meta.(*SddcManagerClient)
+
+	hostsById, err := cluster.GetAllHosts(ctx, vcfClient.ApiClient)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	// hosts that are no longer commissioned are removed, so that they are commissioned again
+	var hostsList []interface{}
+	for _, hostRaw := range data.Get("host").(*schema.Set).List() {
+		host := hostRaw.(map[string]interface{})
+		hostObj, ok := hostsById[host["id"].(string)]
+		if !ok {
+			continue
+		}
+		// the configured FQDN is kept if it differs only in case, so that the host is not replaced in the set
+		if !strings.EqualFold(host["fqdn"].(string), hostObj.Fqdn) {
+			host["fqdn"] = hostObj.Fqdn
+		}
+		host["status"] = hostObj.Status
+		if hostObj.Networkpool != nil && hostObj.Networkpool.ID != nil {
+			host["network_pool_id"] = *hostObj.Networkpool.ID
+		}
+		hostsList = append(hostsList, host)
+	}
+	_ = data.Set("host", resource_utils.HashPasswordsInList(hostsList, "password"))
+
+	return nil
+}
+
+func resourceHostsCommissionUpdate(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	vcfClient := meta.(*SddcManagerClient)
+
+	oldHostsValue, newHostsValue := data.GetChange("host")
+	oldHostsList, newHostsList := oldHostsValue.(*schema.Set).List(), newHostsValue.(*schema.Set).List()
+	err := validateCommissionedHostChanges(oldHostsList, newHostsList)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	oldHosts := createFqdnToHostMap(oldHostsList)
+	var addedHosts []interface{}
+	for _, newHostRaw := range newHostsList {
+		newHost := newHostRaw.(map[string]interface{})
+		oldHost, exists := oldHosts[strings.ToLower(newHost["fqdn"].(string))]
+		if !exists {
+			addedHosts = append(addedHosts, newHost)
+			continue
+		}
+		// the ID is computed, so it is always taken from the old state
+		newHost["id"] = oldHost["id"]
+	}
+
+	newHosts := createFqdnToHostMap(newHostsList)
+	var removedHostFqdns []string
+	for fqdn, oldHost := range oldHosts {
+		if _, exists := newHosts[fqdn]; !exists {
+			removedHostFqdns = append(removedHostFqdns, oldHost["fqdn"].(string))
+		}
+	}
+
+	var diags diag.Diagnostics
+	if len(addedHosts) > 0 {
+		var hostIdsByFqdn map[string]string
+		hostIdsByFqdn, diags = commissionHosts(ctx, tryConvertToHostCommissionSpecs(addedHosts), vcfClient)
+		if diags.HasError() {
+			return diags
+		}
+		setCommissionedHostIds(addedHosts, hostIdsByFqdn)
+	}
+	if len(removedHostFqdns) > 0 {
+		decommissionDiags := decommissionHosts(ctx, removedHostFqdns, vcfClient)
+		if decommissionDiags.HasError() {
+			return append(diags, decommissionDiags...)
+		}
+	}
+	_ = data.Set("host", newHostsList)
+
+	return append(diags, resourceHostsCommissionRead(ctx, data, meta)...)
+}
+
+// resourceHostsCommissionImport imports a group of commissioned hosts from a comma-separated list of
+// host IDs or FQDNs. The credentials of the hosts are not returned by the VCF API and are left empty.
+func resourceHostsCommissionImport(ctx context.Context, data *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	vcfClient := meta.(*SddcManagerClient)
+
+	hostsById, err := cluster.GetAllHosts(ctx, vcfClient.ApiClient)
+	if err != nil {
+		return nil, err
+	}
+
+	var hostsList []interface{}
+	var hostIds []string
+	for _, hostRef := range strings.Split(data.Id(), ",") {
+		hostObj := findHostByIdOrFqdn(hostsById, strings.TrimSpace(hostRef))
+		if hostObj == nil {
+			return nil, fmt.Errorf("host %q not found", hostRef)
+		}
+		host := map[string]interface{}{
+			"id":           hostObj.ID,
+			"fqdn":         hostObj.Fqdn,
+			"storage_type": hostObj.CompatibleStorageType,
+			"status":       hostObj.Status,
+		}
+		if hostObj.Networkpool != nil && hostObj.Networkpool.ID != nil {
+			host["network_pool_id"] = *hostObj.Networkpool.ID
+		}
+		hostsList = append(hostsList, host)
+		hostIds = append(hostIds, hostObj.ID)
+	}
+	_ = data.Set("host", hostsList)
+	_ = data.Set("deletion_protection", true)
+	data.SetId(hostsCommissionId(hostIds))
+
+	return []*schema.ResourceData{data}, nil
+}
+
+func resourceHostsCommissionDelete(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	vcfClient := meta.(*SddcManagerClient)
+
+	err := resource_utils.CheckDeletionProtection(data.Get("deletion_protection"), "hosts commission", data.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	var fqdns []string
+	for _, hostRaw := range data.Get("host").(*schema.Set).List() {
+		fqdns = append(fqdns, hostRaw.(map[string]interface{})["fqdn"].(string))
+	}
+	if len(fqdns) == 0 {
+		return nil
+	}
+	return decommissionHosts(ctx, fqdns, vcfClient)
+}
+
+// validateCommissionedHostChangesInDiff fails the plan if an attribute of a commissioned host changes.
+// The hosts are hashed by FQDN, so the changes are read from the raw configuration instead of the diff.
+func validateCommissionedHostChangesInDiff(_ context.Context, diff *schema.ResourceDiff, _ interface{}) error {
+	if len(diff.Id()) == 0 {
+		return nil
+	}
+	rawConfig := diff.GetRawConfig()
+	if rawConfig.IsNull() || !rawConfig.IsKnown() {
+		return nil
+	}
+	hostsConfig := rawConfig.GetAttr("host")
+	if !hostsConfig.IsWhollyKnown() {
+		return nil
+	}
+	hostsResource := &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"host": ResourceHostsCommission().Schema["host"],
+		},
+	}
+	configState, err := hostsResource.ShimInstanceStateFromValue(
+		cty.ObjectVal(map[string]cty.Value{"id": cty.StringVal(diff.Id()), "host": hostsConfig}))
+	if err != nil {
+		return err
+	}
+	newHostsList := hostsResource.Data(configState).Get("host").(*schema.Set).List()
+	oldHostsValue, _ := diff.GetChange("host")
+	return validateCommissionedHostChanges(oldHostsValue.(*schema.Set).List(), newHostsList)
+}
+
+// validateCommissionedHostChanges returns an error if the password, the network pool, the storage type
+// or the username of a host present in both the old and the new hosts list changes. The attributes that
+// are empty in the old list, e.g. the credentials of imported hosts, are not compared.
+func validateCommissionedHostChanges(oldHostsList, newHostsList []interface{}) error {
+	oldHosts := createFqdnToHostMap(oldHostsList)
+	for _, newHostRaw := range newHostsList {
+		newHost := newHostRaw.(map[string]interface{})
+		oldHost, exists := oldHosts[strings.ToLower(newHost["fqdn"].(string))]
+		if !exists {
+			continue
+		}
+		// the state holds the hash of the password, unless the password is unchanged and its diff is suppressed
+		newPassword, oldPassword := newHost["password"].(string), oldHost["password"].(string)
+		if len(oldPassword) > 0 && newPassword != oldPassword &&
+			!resource_utils.PasswordMatchesHash(newPassword, oldPassword) {
+			return fmt.Errorf("changing \"password\" of the commissioned host %q is not supported, "+
+				"remove the host and add it again", newHost["fqdn"])
+		}
+		for _, attributeName := range []string{"network_pool_id", "storage_type", "username"} {
+			oldValue, _ := oldHost[attributeName].(string)
+			if len(oldValue) > 0 && oldValue != newHost[attributeName] {
+				return fmt.Errorf("changing %q of the commissioned host %q is not supported, "+
+					"remove the host and add it again", attributeName, newHost["fqdn"])
+			}
+		}
+	}
+	return nil
+}
+
+// hashCommissionedHost hashes the hosts by FQDN, which is case-insensitive.
+func hashCommissionedHost(v interface{}) int {
+	fqdn, _ := v.(map[string]interface{})["fqdn"].(string)
+	return schema.HashString(strings.ToLower(fqdn))
+}
+
+func createFqdnToHostMap(hostsList []interface{}) map[string]map[string]interface{} {
+	result := make(map[string]map[string]interface{}, len(hostsList))
+	for _, hostRaw := range hostsList {
+		host := hostRaw.(map[string]interface{})
+		result[strings.ToLower(host["fqdn"].(string))] = host
+	}
+	return result
+}
+
+func findHostByIdOrFqdn(hostsById map[string]*models.Host, hostRef string) *models.Host {
+	if hostObj, ok := hostsById[hostRef]; ok {
+		return hostObj
+	}
+	for _, hostObj := range hostsById {
+		if strings.EqualFold(hostObj.Fqdn, hostRef) {
+			return hostObj
+		}
+	}
+	return nil
+}
+
+// hostsCommissionId the ID of the resource is derived from the IDs of the hosts commissioned on creation.
+func hostsCommissionId(hostIds []string) string {
+	sort.Strings(hostIds)
+	return strconv.Itoa(schema.HashString(strings.Join(hostIds, ",")))
+}
+
+func tryConvertToHostCommissionSpecs(hostsList []interface{}) []*models.HostCommissionSpec {
+	var result []*models.HostCommissionSpec
+	for _, hostRaw := range hostsList {
+		host := hostRaw.(map[string]interface{})
+		result = append(result, &models.HostCommissionSpec{
+			Fqdn:          resource_utils.ToStringPointer(host["fqdn"]),
+			NetworkPoolID: resource_utils.ToStringPointer(host["network_pool_id"]),
+			StorageType:   resource_utils.ToStringPointer(host["storage_type"]),
+			Username:      resource_utils.ToStringPointer(host["username"]),
+			Password:      resource_utils.ToStringPointer(host["password"]),
+		})
+	}
+	return result
+}
+
+func setCommissionedHostIds(hostsList []interface{}, hostIdsByFqdn map[string]string) {
+	for _, hostRaw := range hostsList {
+		host := hostRaw.(map[string]interface{})
+		host["id"] = hostIdsByFqdn[host["fqdn"].(string)]
+	}
+}
+
+// commissionHosts validates the provided host commission specs and commissions all hosts in a single
+// task. The IDs of the commissioned hosts are returned, indexed by FQDN.
+func commissionHosts(ctx context.Context, commissionSpecs []*models.HostCommissionSpec,
+	vcfClient *SddcManagerClient) (map[string]string, diag.Diagnostics) {
+	apiClient := vcfClient.ApiClient
+
+	diags := validateHostCommissionSpecs(ctx, commissionSpecs, vcfClient)
+	if diags.HasError() {
+		return nil, diags
+	}
+
+	commissionHostsParams := hosts.NewCommissionHostsParamsWithContext(ctx).
+		WithTimeout(constants.DefaultVcfApiCallTimeout)
+	commissionHostsParams.HostCommissionSpecs = commissionSpecs
+	_, accepted, err := apiClient.Hosts.CommissionHosts(commissionHostsParams)
+	if err != nil {
+		return nil, append(diags, validationUtils.ConvertVcfErrorToDiag(err)...)
+	}
+	taskId := accepted.Payload.ID
+	tflog.Info(ctx, fmt.Sprintf("Commission of %d hosts initiated, waiting for task id = %s",
+		len(commissionSpecs), taskId))
+
+	err = vcfClient.WaitForTaskComplete(ctx, taskId, false)
+	if err != nil {
+		return nil, append(diags, diag.FromErr(err)...)
+	}
+
+	hostsById, err := cluster.GetAllHosts(ctx, apiClient)
+	if err != nil {
+		return nil, append(diags, diag.FromErr(err)...)
+	}
+	hostIdsByFqdn := make(map[string]string, len(hostsById))
+	for _, hostObj := range hostsById {
+		hostIdsByFqdn[strings.ToLower(hostObj.Fqdn)] = hostObj.ID
+	}
+	result := make(map[string]string, len(commissionSpecs))
+	for _, commissionSpec := range commissionSpecs {
+		hostId, ok := hostIdsByFqdn[strings.ToLower(*commissionSpec.Fqdn)]
+		if !ok {
+			return nil, append(diags, diag.FromErr(fmt.Errorf("host %q not found after commission task %q",
+				*commissionSpec.Fqdn, taskId))...)
+		}
+		result[*commissionSpec.Fqdn] = hostId
+	}
+	return result, diags
+}
+
+// validateHostCommissionSpecs runs the host commission validation of SDDC Manager and waits for its result.
+// The failed checks are returned as diagnostics, associated with the FQDN of the host they concern.
+func validateHostCommissionSpecs(ctx context.Context, commissionSpecs []*models.HostCommissionSpec,
+	vcfClient *SddcManagerClient) diag.Diagnostics {
+	apiClient := vcfClient.ApiClient
+	validateHostsParams := hosts.NewValidateHostsOperationsParamsWithContext(ctx).
+		WithTimeout(constants.DefaultVcfApiCallTimeout)
+	validateHostsParams.HostCommissionSpecs = commissionSpecs
+
+	okResponse, acceptedResponse, err := apiClient.Hosts.ValidateHostsOperations(validateHostsParams)
+	if err != nil {
+		return validationUtils.ConvertVcfErrorToDiag(err)
+	}
+	var validationResult *models.Validation
+	if okResponse != nil {
+		validationResult = okResponse.Payload
+	}
+	if acceptedResponse != nil {
+		validationResult = acceptedResponse.Payload
+	}
+
+	for validationResult != nil && validationResult.ExecutionStatus == "IN_PROGRESS" {
+		select {
+		case <-ctx.Done():
+			return diag.FromErr(ctx.Err())
+		case <-time.After(hostCommissionValidationPollInterval):
+		}
+		getValidationParams := hosts.NewGetValidationForCommissionHostsParamsWithContext(ctx).
+			WithTimeout(constants.DefaultVcfApiCallTimeout)
+		getValidationParams.ID = validationResult.ID
+		getValidationResult, err := apiClient.Hosts.GetValidationForCommissionHosts(getValidationParams)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		validationResult = getValidationResult.Payload
+	}
+
+	if validationUtils.HasValidationFailed(validationResult) {
+		var fqdns []string
+		for _, commissionSpec := range commissionSpecs {
+			fqdns = append(fqdns, *commissionSpec.Fqdn)
+		}
+		return validationUtils.AssociateDiagsWithResources(
+			validationUtils.ConvertValidationResultToDiag(validationResult), fqdns)
+	}
+	return nil
+}
+
+// decommissionHosts decommissions the hosts with the provided FQDNs in a single task.
+func decommissionHosts(ctx context.Context, fqdns []string, vcfClient *SddcManagerClient) diag.Diagnostics {
+	decommissionHostsParams := hosts.NewDecommissionHostsParamsWithContext(ctx).
+		WithTimeout(constants.DefaultVcfApiCallTimeout)
+	for _, fqdn := range fqdns {
+		decommissionHostsParams.HostDecommissionSpecs = append(decommissionHostsParams.HostDecommissionSpecs,
+			&models.HostDecommissionSpec{Fqdn: resource_utils.ToStringPointer(fqdn)})
+	}
+
+	_, accepted, err := vcfClient.ApiClient.Hosts.DecommissionHosts(decommissionHostsParams)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	tflog.Info(ctx, fmt.Sprintf("Decommission of hosts %s initiated, waiting for task id = %s",
+		strings.Join(fqdns, ", "), accepted.Payload.ID))
+	err = vcfClient.WaitForTaskComplete(ctx, accepted.Payload.ID, false)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	return nil
+}
@@ -0,0 +1,236 @@
+/* Copyright 2023 VMware, Inc.
+   SPDX-License-Identifier: MPL-2.0 */
+
+package provider
+
+import (
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/vmware/terraform-provider-vcf/internal/constants"
+	"github.com/vmware/vcf-sdk-go/models"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestAccResourceVcfHostsCommission(t *testing.T) {
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		CheckDestroy:      testCheckVcfHostsCommissionDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccVcfHostsCommissionConfig(
+					os.Getenv(constants.VcfTestHost2Fqdn),
+					os.Getenv(constants.VcfTestHost2Pass),
+					os.Getenv(constants.VcfTestHost3Fqdn),
+					os.Getenv(constants.VcfTestHost3Pass)),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrSet("vcf_hosts_commission.hosts", "id"),
+					resource.TestCheckResourceAttr("vcf_hosts_commission.hosts", "host.#", "2"),
+					resource.TestCheckTypeSetElemNestedAttrs("vcf_hosts_commission.hosts", "host.*",
+						map[string]string{
+							"fqdn":   os.Getenv(constants.VcfTestHost2Fqdn),
+							"status": "UNASSIGNED_USEABLE",
+						}),
+					resource.TestCheckTypeSetElemNestedAttrs("vcf_hosts_commission.hosts", "host.*",
+						map[string]string{
+							"fqdn":   os.Getenv(constants.VcfTestHost3Fqdn),
+							"status": "UNASSIGNED_USEABLE",
+						}),
+				),
+			},
+			{
+				ResourceName:      "vcf_hosts_commission.hosts",
+				ImportState:       true,
+				ImportStateIdFunc: testAccVcfHostsCommissionImportStateId,
+				ImportStateVerify: true,
+				// the credentials of the hosts are not returned by the VCF API
+				// and imported hosts are always protected from deletion
+				ImportStateVerifyIgnore: []string{"host", "deletion_protection"},
+				ImportStateCheck:        hostsCommissionImportStateCheck,
+			},
+		},
+	})
+}
+
+func testAccVcfHostsCommissionImportStateId(state *terraform.State) (string, error) {
+	hostsCommission, ok := state.RootModule().Resources["vcf_hosts_commission.hosts"]
+	if !ok {
+		return "", fmt.Errorf("vcf_hosts_commission.hosts not found in the state")
+	}
+	var hostRefs []string
+	for attributeName, attributeValue := range hostsCommission.Primary.Attributes {
+		if strings.HasPrefix(attributeName, "host.") && strings.HasSuffix(attributeName, ".fqdn") {
+			hostRefs = append(hostRefs, attributeValue)
+		}
+	}
+	return strings.Join(hostRefs, ","), nil
+}
+
+func hostsCommissionImportStateCheck(states []*terraform.InstanceState) error {
+	for _, state := range states {
+		if state.Ephemeral.Type != "vcf_hosts_commission" {
+			continue
+		}
+		if state.Attributes["host.#"] != "2" {
+			return fmt.Errorf("expected 2 imported hosts, got %s", state.Attributes["host.#"])
+		}
+		if state.Attributes["deletion_protection"] != "true" {
+			return fmt.Errorf("imported hosts are not protected from deletion")
+		}
+	}
+	return nil
+}
+
+func TestValidateCommissionedHostChanges(t *testing.T) {
+	hostsConfig := func(hosts ...map[string]interface{}) map[string]interface{} {
+		var hostsList []interface{}
+		for _, host := range hosts {
+			hostsList = append(hostsList, host)
+		}
+		return map[string]interface{}{"host": hostsList}
+	}
+	host := func(fqdn string, attributes ...string) map[string]interface{} {
+		result := map[string]interface{}{
+			"fqdn":            fqdn,
+			"network_pool_id": "network-pool-1",
+			"storage_type":    "VSAN",
+			"username":        "root",
+			"password":        "VMware123!",
+		}
+		for i := 0; i+1 < len(attributes); i += 2 {
+			result[attributes[i]] = attributes[i+1]
+		}
+		return result
+	}
+	oldConfig := hostsConfig(host("esxi-1.vrack.vsphere.local"), host("esxi-2.vrack.vsphere.local"))
+
+	var changeTests = []struct {
+		name        string
+		newConfig   map[string]interface{}
+		expectedErr string
+	}{
+		{"unchanged", oldConfig, ""},
+		{"fqdn case", hostsConfig(host("ESXi-1.vrack.vsphere.local"), host("esxi-2.vrack.vsphere.local")), ""},
+		{"add and remove", hostsConfig(host("esxi-1.vrack.vsphere.local"), host("esxi-3.vrack.vsphere.local",
+			"password", "VMware1234!")), ""},
+		{"password", hostsConfig(host("esxi-1.vrack.vsphere.local"), host("esxi-2.vrack.vsphere.local",
+			"password", "VMware1234!")), "changing \"password\" of the commissioned host \"esxi-2.vrack.vsphere.local\""},
+		{"network pool", hostsConfig(host("esxi-1.vrack.vsphere.local", "network_pool_id", "network-pool-2"),
+			host("esxi-2.vrack.vsphere.local")), "changing \"network_pool_id\" of the commissioned host"},
+		{"storage type", hostsConfig(host("esxi-1.vrack.vsphere.local", "storage_type", "NFS"),
+			host("esxi-2.vrack.vsphere.local")), "changing \"storage_type\" of the commissioned host"},
+		{"username", hostsConfig(host("esxi-1.vrack.vsphere.local", "username", "admin"),
+			host("esxi-2.vrack.vsphere.local")), "changing \"username\" of the commissioned host"},
+	}
+	for _, changeTest := range changeTests {
+		err := planResourceChange(t, ResourceHostsCommission(), "hosts-1", oldConfig, changeTest.newConfig)
+		if len(changeTest.expectedErr) == 0 {
+			if err != nil {
+				t.Errorf("%s: unexpected error %v", changeTest.name, err)
+			}
+		} else if err == nil || !strings.Contains(err.Error(), changeTest.expectedErr) {
+			t.Errorf("%s: expected error containing %q, got %v", changeTest.name, changeTest.expectedErr, err)
+		}
+	}
+
+	// the credentials of imported hosts are not known
+	importedHosts := []interface{}{map[string]interface{}{"fqdn": "esxi-1.vrack.vsphere.local",
+		"network_pool_id": "network-pool-1", "storage_type": "VSAN", "username": "", "password": ""}}
+	err := validateCommissionedHostChanges(importedHosts, oldConfig["host"].([]interface{}))
+	if err != nil {
+		t.Errorf("unexpected error for imported hosts %v", err)
+	}
+}
+
+func TestFindHostByIdOrFqdn(t *testing.T) {
+	hostsById := map[string]*models.Host{
+		"host-1": {ID: "host-1", Fqdn: "esxi-1.vrack.vsphere.local"},
+		"host-2": {ID: "host-2", Fqdn: "esxi-2.vrack.vsphere.local"},
+	}
+	var findTests = []struct {
+		hostRef        string
+		expectedHostId string
+	}{
+		{"host-2", "host-2"},
+		{"esxi-1.vrack.vsphere.local", "host-1"},
+		{"ESXi-2.vrack.vsphere.local", "host-2"},
+		{"esxi-3.vrack.vsphere.local", ""},
+	}
+	for _, findTest := range findTests {
+		hostObj := findHostByIdOrFqdn(hostsById, findTest.hostRef)
+		if (hostObj == nil && len(findTest.expectedHostId) > 0) ||
+			(hostObj != nil && hostObj.ID != findTest.expectedHostId) {
+			t.Errorf("%s: expected host %q, got %+v", findTest.hostRef, findTest.expectedHostId, hostObj)
+		}
+	}
+}
+
+func testAccVcfHostsCommissionConfig(host2Fqdn, host2SshPassword, host3Fqdn, host3SshPassword string) string {
+	return fmt.Sprintf(`
+	resource "vcf_network_pool" "commission_pool" {
+		name    = "commission-pool"
+		network {
+			gateway   = "192.168.10.1"
+			mask      = "255.255.255.0"
+			mtu       = 9000
+			subnet    = "192.168.10.0"
+			type      = "VSAN"
+			vlan_id   = 100
+			ip_pools {
+				start = "192.168.10.5"
+				end   = "192.168.10.50"
+			}
+		}
+		network {
+			gateway   = "192.168.11.1"
+			mask      = "255.255.255.0"
+			mtu       = 9000
+			subnet    = "192.168.11.0"
+			type      = "vMotion"
+			vlan_id   = 100
+			ip_pools {
+			  start = "192.168.11.5"
+			  end   = "192.168.11.50"
+			}
+		  }
+	}
+
+	resource "vcf_hosts_commission" "hosts" {
+		host {
+			fqdn            = %q
+			username        = "root"
+			password        = %q
+			network_pool_id = vcf_network_pool.commission_pool.id
+			storage_type    = "VSAN"
+		}
+		host {
+			fqdn            = %q
+			username        = "root"
+			password        = %q
+			network_pool_id = vcf_network_pool.commission_pool.id
+			storage_type    = "VSAN"
+		}
+		deletion_protection = false
+	}`, host2Fqdn, host2SshPassword, host3Fqdn, host3SshPassword)
+}
+
+func testCheckVcfHostsCommissionDestroy(_ *terraform.State) error {
+	vcfClient := testAccProvider.Meta().(*SddcManagerClient)
+	apiClient := vcfClient.ApiClient
+
+	hosts, err := apiClient.Hosts.GetHosts(nil)
+	if err != nil {
+		return err
+	}
+
+	for _, host := range hosts.Payload.Elements {
+		if host.Fqdn == os.Getenv(constants.VcfTestHost2Fqdn) || host.Fqdn == os.Getenv(constants.VcfTestHost3Fqdn) {
+			return fmt.Errorf("found host %q", host.ID)
+		}
+	}
+
+	return nil
+}
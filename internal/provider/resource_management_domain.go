@@ -0,0 +1,337 @@
+/* Copyright 2023 VMware, Inc.
+   SPDX-License-Identifier: MPL-2.0 */
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/vmware/terraform-provider-vcf/internal/cluster"
+	"github.com/vmware/terraform-provider-vcf/internal/constants"
+	"github.com/vmware/terraform-provider-vcf/internal/resource_utils"
+	"github.com/vmware/vcf-sdk-go/client"
+	"github.com/vmware/vcf-sdk-go/client/clusters"
+	"github.com/vmware/vcf-sdk-go/client/domains"
+	"github.com/vmware/vcf-sdk-go/models"
+	"time"
+)
+
+const managementDomainType = "MANAGEMENT"
+
+// ResourceManagementDomain adopts the management domain, created during the bring-up of
+// VCF, so that its mutable parts can be managed. The domain is never deleted by the resource.
+func ResourceManagementDomain() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceManagementDomainCreate,
+		ReadContext:   resourceManagementDomainRead,
+		UpdateContext: resourceManagementDomainUpdate,
+		DeleteContext: resourceManagementDomainDelete,
+		CustomizeDiff: func(ctx context.Context, diff *schema.ResourceDiff, meta interface{}) error {
+			err := validateClusterChangesInDomain(diff)
+			if err != nil {
+				return err
+			}
+			return validateAdoptedClustersInManagementDomain(ctx, diff, meta.(*SddcManagerClient))
+		},
+		Importer: &schema.ResourceImporter{
+			StateContext: func(ctx context.Context, data *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+				vcfClient := meta.(*SddcManagerClient)
+				domain, err := getManagementDomain(ctx, vcfClient.ApiClient)
+				if err != nil {
+					return nil, err
+				}
+				if domain.ID != data.Id() {
+					return nil, fmt.Errorf("domain %q is not the management domain, use vcf_domain instead", data.Id())
+				}
+				return []*schema.ResourceData{data}, nil
+			},
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(4 * time.Hour),
+			Read:   schema.DefaultTimeout(20 * time.Minute),
+			Update: schema.DefaultTimeout(4 * time.Hour),
+			Delete: schema.DefaultTimeout(1 * time.Minute),
+		},
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ValidateFunc: validation.StringLenBetween(3, 20),
+				Description:  "Name of the management domain (from 3 to 20 characters)",
+			},
+			"cluster": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Description: "Specification representing the clusters managed in the management domain. " +
+					"Existing clusters with the same name are adopted, their hosts and NFS and vVol datastores are " +
+					"changed to match the configuration. Clusters not present in the configuration are left unchanged",
+				Set:  hashDomainCluster,
+				Elem: clusterSubresourceSchema(),
+			},
+			"vcenter_id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "ID of the vCenter Server instance",
+			},
+			"vcenter_fqdn": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Fully qualified domain name of the vCenter Server instance",
+			},
+			"status": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Status of the management domain",
+			},
+			"type": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Type of the domain",
+			},
+			"sso_id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "ID of the SSO domain associated with the management domain",
+			},
+			"sso_name": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Name of the SSO domain associated with the management domain",
+			},
+			"is_management_sso_domain": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Shows whether the domain is joined to the management domain SSO",
+			},
+		},
+	}
+}
+
+func resourceManagementDomainCreate(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	vcfClient := meta.(*SddcManagerClient)
+
+	domain, err := getManagementDomain(ctx, vcfClient.ApiClient)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	data.SetId(domain.ID)
+	tflog.Info(ctx, fmt.Sprintf("Adopting management domain %q", domain.ID))
+
+	if name, ok := data.GetOk("name"); ok && name.(string) != domain.Name {
+		diags := updateDomain(ctx, domain.ID, &models.DomainUpdateSpec{Name: name.(string)}, vcfClient)
+		if diags != nil {
+			return diags
+		}
+	}
+
+	clustersList := data.Get("cluster").(*schema.Set).List()
+	diags := handleClusterChangesInManagementDomain(ctx, domain, nil, clustersList, vcfClient)
+	if diags.HasError() {
+		return diags
+	}
+	// store the IDs of the resolved hosts
+	_ = data.Set("cluster", clustersList)
+
+	return append(diags, resourceManagementDomainRead(ctx, data, meta)...)
+}
+
+func resourceManagementDomainRead(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	vcfClient := meta.(*SddcManagerClient)
+	apiClient := vcfClient.ApiClient
+
+	getDomainParams := domains.NewGetDomainParamsWithContext(ctx).
+		WithTimeout(constants.DefaultVcfApiCallTimeout)
+	getDomainParams.ID = data.Id()
+	domainResult, err := apiClient.Domains.GetDomain(getDomainParams)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	domain := domainResult.Payload
+
+	_ = data.Set("name", domain.Name)
+	_ = data.Set("status", domain.Status)
+	_ = data.Set("type", domain.Type)
+	_ = data.Set("sso_id", domain.SSOID)
+	_ = data.Set("sso_name", domain.SSOName)
+	_ = data.Set("is_management_sso_domain", domain.IsManagementSSODomain)
+	if len(domain.VCENTERS) < 1 {
+		return diag.FromErr(fmt.Errorf("no vCenter Server instance found for domain %q", data.Id()))
+	}
+	_ = data.Set("vcenter_id", domain.VCENTERS[0].ID)
+	_ = data.Set("vcenter_fqdn", domain.VCENTERS[0].Fqdn)
+
+	err = readAndSetClustersDataToDomainResource(domain.Clusters, ctx, data, apiClient)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	return nil
+}
+
+func resourceManagementDomainUpdate(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	vcfClient := meta.(*SddcManagerClient)
+
+	if data.HasChange("name") {
+		diags := updateDomain(ctx, data.Id(), createDomainUpdateSpec(data, false), vcfClient)
+		if diags != nil {
+			return diags
+		}
+	}
+
+	var diags diag.Diagnostics
+	if data.HasChange("cluster") {
+		domain, err := getManagementDomain(ctx, vcfClient.ApiClient)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		oldClustersValue, newClustersValue := data.GetChange("cluster")
+		newClustersList := newClustersValue.(*schema.Set).List()
+		diags = handleClusterChangesInManagementDomain(ctx, domain, oldClustersValue.(*schema.Set).List(),
+			newClustersList, vcfClient)
+		if diags.HasError() {
+			return diags
+		}
+		// store the IDs of the resolved hosts
+		_ = data.Set("cluster", newClustersList)
+	}
+
+	return append(diags, resourceManagementDomainRead(ctx, data, meta)...)
+}
+
+// resourceManagementDomainDelete only removes the management domain from the Terraform state.
+func resourceManagementDomainDelete(ctx context.Context, data *schema.ResourceData, _ interface{}) diag.Diagnostics {
+	tflog.Warn(ctx, fmt.Sprintf("Management domain %q is only removed from the state, it is not deleted", data.Id()))
+	data.SetId("")
+	return nil
+}
+
+// handleClusterChangesInManagementDomain adopts the configured clusters that already exist in the
+// management domain and applies the remaining changes in the same way as for workload domains.
+// Clusters removed from the configuration are only removed from the state, they are never deleted.
+func handleClusterChangesInManagementDomain(ctx context.Context, domain *models.Domain, oldClustersList,
+	newClustersList []interface{}, vcfClient *SddcManagerClient) diag.Diagnostics {
+	managedClustersList, err := adoptExistingClusters(ctx, domain, oldClustersList, newClustersList, vcfClient.ApiClient)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	// no cluster is removed, the deletion protection is only a safeguard
+	return handleClusterChangesInDomain(ctx, domain.ID, newClustersList, managedClustersList, true, vcfClient)
+}
+
+// adoptExistingClusters returns the clusters in the state, that are still present in the configuration,
+// together with the configured clusters that already exist in the management domain and are not in the
+// state. The adopted clusters are built from the actual clusters, so that the differences to the
+// configuration, e.g. in the hosts, are applied.
+func adoptExistingClusters(ctx context.Context, domain *models.Domain, oldClustersList, newClustersList []interface{},
+	apiClient *client.VcfClient) ([]interface{}, error) {
+	existingClusters, err := getClustersInDomain(ctx, domain, apiClient)
+	if err != nil {
+		return nil, err
+	}
+
+	managedClustersList := getManagedClusters(oldClustersList, newClustersList)
+	managedClustersMap := resource_utils.CreateKeyToObjectMap(managedClustersList, "name")
+	var hostsById map[string]*models.Host
+	for _, newClusterRaw := range newClustersList {
+		newCluster := newClusterRaw.(map[string]interface{})
+		clusterName := newCluster["name"].(string)
+		existingCluster, exists := existingClusters[clusterName]
+		if _, isManaged := managedClustersMap[clusterName]; isManaged || !exists {
+			continue
+		}
+		if hostsById == nil {
+			hostsById, err = cluster.GetHostsInDomain(ctx, domain.ID, apiClient)
+			if err != nil {
+				return nil, err
+			}
+		}
+		tflog.Info(ctx, fmt.Sprintf("Adopting cluster %q in management domain", clusterName))
+		adoptedCluster, err := cluster.GetExistingCluster(ctx, existingCluster, newCluster, hostsById, apiClient)
+		if err != nil {
+			return nil, err
+		}
+		managedClustersList = append(managedClustersList, adoptedCluster)
+	}
+	return managedClustersList, nil
+}
+
+// validateAdoptedClustersInManagementDomain fails the plan if a configured cluster, that already exists in the
+// management domain and is not in the state yet, differs from the actual cluster in a way that can't be applied.
+func validateAdoptedClustersInManagementDomain(ctx context.Context, diff *schema.ResourceDiff, vcfClient *SddcManagerClient) error {
+	if !diff.HasChange("cluster") {
+		return nil
+	}
+	newClustersList, err := getClustersFromConfig(diff)
+	if newClustersList == nil || err != nil {
+		return err
+	}
+	oldClustersValue, _ := diff.GetChange("cluster")
+	oldClustersList := oldClustersValue.(*schema.Set).List()
+	oldClustersMap := resource_utils.CreateKeyToObjectMap(oldClustersList, "name")
+	hasNewClusters := false
+	for _, newClusterRaw := range newClustersList {
+		if _, isPresent := oldClustersMap[newClusterRaw.(map[string]interface{})["name"].(string)]; !isPresent {
+			hasNewClusters = true
+		}
+	}
+	if !hasNewClusters {
+		return nil
+	}
+
+	domain, err := getManagementDomain(ctx, vcfClient.ApiClient)
+	if err != nil {
+		return err
+	}
+	managedClustersList, err := adoptExistingClusters(ctx, domain, oldClustersList, newClustersList, vcfClient.ApiClient)
+	if err != nil {
+		return err
+	}
+	return validateClusterUpdatesInDomain(managedClustersList, newClustersList)
+}
+
+// getManagedClusters returns the clusters in the state, that are still present in the configuration.
+func getManagedClusters(oldClustersList, newClustersList []interface{}) []interface{} {
+	newClustersMap := resource_utils.CreateKeyToObjectMap(newClustersList, "name")
+	var result []interface{}
+	for _, oldClusterRaw := range oldClustersList {
+		clusterName := oldClusterRaw.(map[string]interface{})["name"].(string)
+		if _, isPresent := newClustersMap[clusterName]; isPresent {
+			result = append(result, oldClusterRaw)
+		}
+	}
+	return result
+}
+
+func getManagementDomain(ctx context.Context, apiClient *client.VcfClient) (*models.Domain, error) {
+	managementDomains, err := getDomains(ctx, managementDomainType, apiClient)
+	if err != nil {
+		return nil, err
+	}
+	if len(managementDomains) != 1 || managementDomains[0] == nil {
+		return nil, fmt.Errorf("expected exactly one management domain, found %d", len(managementDomains))
+	}
+	return managementDomains[0], nil
+}
+
+// getClustersInDomain returns the clusters in the provided domain, indexed by name.
+func getClustersInDomain(ctx context.Context, domain *models.Domain,
+	apiClient *client.VcfClient) (map[string]*models.Cluster, error) {
+	result := make(map[string]*models.Cluster, len(domain.Clusters))
+	for _, clusterRef := range domain.Clusters {
+		getClusterParams := clusters.NewGetClusterParamsWithContext(ctx).
+			WithTimeout(constants.DefaultVcfApiCallTimeout)
+		getClusterParams.ID = *clusterRef.ID
+		clusterResult, err := apiClient.Clusters.GetCluster(getClusterParams)
+		if err != nil {
+			return nil, err
+		}
+		result[clusterResult.Payload.Name] = clusterResult.Payload
+	}
+	return result, nil
+}
@@ -0,0 +1,79 @@
+/*
+ *  Copyright 2023 VMware, Inc.
+ *    SPDX-License-Identifier: MPL-2.0
+ */
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"testing"
+)
+
+func TestAccResourceVcfManagementDomain(t *testing.T) {
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		CheckDestroy:      testCheckVcfManagementDomainNotDestroyed,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccVcfManagementDomainConfig(),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrSet("vcf_management_domain.mgmt", "id"),
+					resource.TestCheckResourceAttrSet("vcf_management_domain.mgmt", "name"),
+					resource.TestCheckResourceAttrSet("vcf_management_domain.mgmt", "vcenter_id"),
+					resource.TestCheckResourceAttrSet("vcf_management_domain.mgmt", "vcenter_fqdn"),
+					resource.TestCheckResourceAttrSet("vcf_management_domain.mgmt", "status"),
+					resource.TestCheckResourceAttr("vcf_management_domain.mgmt", "type", "MANAGEMENT"),
+					resource.TestCheckResourceAttrSet("vcf_management_domain.mgmt", "sso_id"),
+					resource.TestCheckResourceAttrSet("vcf_management_domain.mgmt", "sso_name"),
+				),
+			},
+			{
+				ResourceName:      "vcf_management_domain.mgmt",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestGetManagedClusters(t *testing.T) {
+	oldClustersList := []interface{}{
+		map[string]interface{}{"id": "cluster-1", "name": "sfo-m01-cl01", "is_default": true},
+		map[string]interface{}{"id": "cluster-2", "name": "sfo-m01-cl02"},
+	}
+	newClustersList := []interface{}{
+		map[string]interface{}{"name": "sfo-m01-cl02"},
+		map[string]interface{}{"name": "sfo-m01-cl03"},
+	}
+
+	// the clusters removed from the configuration are left unchanged, so they are not passed on for deletion
+	managedClusters := getManagedClusters(oldClustersList, newClustersList)
+	if len(managedClusters) != 1 || managedClusters[0].(map[string]interface{})["id"] != "cluster-2" {
+		t.Errorf("expected only cluster %q to be managed, got %v", "cluster-2", managedClusters)
+	}
+}
+
+func testAccVcfManagementDomainConfig() string {
+	return `
+	resource "vcf_management_domain" "mgmt" {
+	}`
+}
+
+// testCheckVcfManagementDomainNotDestroyed verifies that the management domain still exists
+// after the resource has been destroyed.
+func testCheckVcfManagementDomainNotDestroyed(_ *terraform.State) error {
+	vcfClient := testAccProvider.Meta().(*SddcManagerClient)
+	apiClient := vcfClient.ApiClient
+
+	_, err := getManagementDomain(context.TODO(), apiClient)
+	if err != nil {
+		return fmt.Errorf("management domain not found after destroy: %w", err)
+	}
+
+	return nil
+}
@@ -10,6 +10,9 @@ import (
 	"fmt"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/vmware/terraform-provider-vcf/internal/constants"
+	"github.com/vmware/terraform-provider-vcf/internal/vcenter"
+	"github.com/vmware/vcf-sdk-go/client/credentials"
+	"github.com/vmware/vcf-sdk-go/client/domains"
 	"github.com/vmware/vcf-sdk-go/client/tasks"
 	"github.com/vmware/vcf-sdk-go/client/tokens"
 	"github.com/vmware/vcf-sdk-go/models"
@@ -197,6 +200,40 @@ func (sddcManagerClient *SddcManagerClient) WaitForTaskComplete(ctx context.Cont
 	}
 }
 
+// WaitForCredentialsTaskComplete Wait for a credentials task, e.g. a password update, till it completes.
+func (sddcManagerClient *SddcManagerClient) WaitForCredentialsTaskComplete(ctx context.Context, taskId string) error {
+	log.Printf("Getting status of credentials task %s", taskId)
+	for {
+		getCredentialsTaskParams := credentials.NewGetCredentialsTaskParamsWithContext(ctx).
+			WithTimeout(constants.DefaultVcfApiCallTimeout)
+		getCredentialsTaskParams.ID = taskId
+		getCredentialsTaskResult, err := sddcManagerClient.ApiClient.Credentials.GetCredentialsTask(getCredentialsTaskParams)
+		if err != nil {
+			return err
+		}
+		task := getCredentialsTaskResult.Payload
+
+		if task.Status == "PENDING" || task.Status == "IN_PROGRESS" {
+			time.Sleep(20 * time.Second)
+			continue
+		}
+
+		if task.Status != "SUCCESSFUL" {
+			errorMsg := fmt.Sprintf("Credentials task with ID = %s , Name: %q is in state %s", taskId, task.Name, task.Status)
+			for _, taskError := range task.Errors {
+				if taskError != nil {
+					errorMsg = fmt.Sprintf("%s: %s", errorMsg, taskError.Message)
+				}
+			}
+			tflog.Error(ctx, errorMsg)
+			return errors.New(errorMsg)
+		}
+
+		log.Printf("Credentials task with ID = %s is in state %s", taskId, task.Status)
+		return nil
+	}
+}
+
 func (sddcManagerClient *SddcManagerClient) GetResourceIdAssociatedWithTask(ctx context.Context, taskId, resourceType string) (string, error) {
 	task, err := sddcManagerClient.getTask(ctx, taskId)
 	if err != nil {
@@ -245,3 +282,50 @@ func (sddcManagerClient *SddcManagerClient) retryTask(ctx context.Context, taskI
 	}
 	return nil
 }
+
+// ConnectToVcenterOfDomain logs in to the vCenter Server of the workload domain with the provided ID,
+// with the SSO administrator credentials that SDDC Manager stores for it.
+func (sddcManagerClient *SddcManagerClient) ConnectToVcenterOfDomain(ctx context.Context, domainId string) (*vcenter.Client, error) {
+	apiClient := sddcManagerClient.ApiClient
+	getDomainParams := domains.NewGetDomainParamsWithContext(ctx).
+		WithTimeout(constants.DefaultVcfApiCallTimeout)
+	getDomainParams.ID = domainId
+	domainResult, err := apiClient.Domains.GetDomain(getDomainParams)
+	if err != nil {
+		return nil, err
+	}
+	domain := domainResult.Payload
+	if len(domain.VCENTERS) < 1 || domain.VCENTERS[0] == nil {
+		return nil, fmt.Errorf("no vCenter Server found for domain %q", domainId)
+	}
+	vcenterFqdn := domain.VCENTERS[0].Fqdn
+
+	resourceType := "PSC"
+	getCredentialsParams := credentials.NewGetCredentialsParamsWithContext(ctx).
+		WithTimeout(constants.DefaultVcfApiCallTimeout).WithResourceType(&resourceType)
+	getCredentialsResult, err := apiClient.Credentials.GetCredentials(getCredentialsParams)
+	if err != nil {
+		return nil, err
+	}
+	// the SSO administrator of the management domain is used if the domain has no SSO domain of its own
+	var ssoCredential *models.Credential
+	for _, credential := range getCredentialsResult.Payload.Elements {
+		if credential == nil || credential.CredentialType == nil || *credential.CredentialType != "SSO" ||
+			credential.Username == nil || credential.Resource == nil {
+			continue
+		}
+		if credential.Resource.ResourceName != nil && *credential.Resource.ResourceName == vcenterFqdn {
+			ssoCredential = credential
+			break
+		}
+		if ssoCredential == nil {
+			ssoCredential = credential
+		}
+	}
+	if ssoCredential == nil {
+		return nil, fmt.Errorf("no SSO credentials found for vCenter Server %q", vcenterFqdn)
+	}
+
+	return vcenter.NewClient(ctx, vcenterFqdn, *ssoCredential.Username, ssoCredential.Password,
+		sddcManagerClient.allowUnverifiedTls)
+}
@@ -0,0 +1,78 @@
+/*
+ *  Copyright 2023 VMware, Inc.
+ *    SPDX-License-Identifier: MPL-2.0
+ */
+
+package resource_utils
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// HashPassword returns a salted bcrypt hash of the password, that is stored in the state instead of
+// the password itself. The password is digested first, because bcrypt accepts at most 72 bytes.
+func HashPassword(password string) string {
+	hash, err := bcrypt.GenerateFromPassword(digestPassword(password), bcrypt.DefaultCost)
+	if err != nil {
+		// the digest is always short enough, so only a failure of the random source ends up here
+		return ""
+	}
+	return string(hash)
+}
+
+// IsPasswordHash returns whether the value is a hash created by HashPassword.
+func IsPasswordHash(value string) bool {
+	_, err := bcrypt.Cost([]byte(value))
+	return err == nil
+}
+
+// PasswordMatchesHash returns whether the password matches the value from the state. States written
+// before the passwords were hashed hold the password itself, which is compared as is.
+func PasswordMatchesHash(password, stateValue string) bool {
+	if !IsPasswordHash(stateValue) {
+		return password == stateValue
+	}
+	return bcrypt.CompareHashAndPassword([]byte(stateValue), digestPassword(password)) == nil
+}
+
+// HashPasswordStateFunc is the StateFunc of the password attributes, that are stored as salted hashes.
+// Values that are already hashed are kept, so that the hash in the plan is not hashed again on apply.
+func HashPasswordStateFunc(value interface{}) string {
+	password, _ := value.(string)
+	if len(password) == 0 || IsPasswordHash(password) {
+		return password
+	}
+	return HashPassword(password)
+}
+
+// SuppressMatchingPasswordDiff is the DiffSuppressFunc of the password attributes, that are stored as
+// salted hashes. The hash of the configured password has a different salt on every plan, so the
+// configured password is compared to the hash in the state instead.
+func SuppressMatchingPasswordDiff(key, oldValue, _ string, data *schema.ResourceData) bool {
+	if len(oldValue) == 0 {
+		return false
+	}
+	password, _ := data.Get(key).(string)
+	return PasswordMatchesHash(password, oldValue)
+}
+
+// HashPasswordsInList replaces the passwords in a list of objects, e.g. the hosts of a cluster,
+// with their hashes before the list is set to the state.
+func HashPasswordsInList(objectsList []interface{}, passwordKey string) []interface{} {
+	for _, objectRaw := range objectsList {
+		object, ok := objectRaw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		object[passwordKey] = HashPasswordStateFunc(object[passwordKey])
+	}
+	return objectsList
+}
+
+func digestPassword(password string) []byte {
+	digest := sha256.Sum256([]byte(password))
+	return []byte(base64.StdEncoding.EncodeToString(digest[:]))
+}
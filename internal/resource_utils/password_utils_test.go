@@ -0,0 +1,43 @@
+/*
+ *  Copyright 2023 VMware, Inc.
+ *    SPDX-License-Identifier: MPL-2.0
+ */
+
+package resource_utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPasswordHash(t *testing.T) {
+	password := "VMware123!"
+	hash := HashPassword(password)
+	if hash == password || !IsPasswordHash(hash) {
+		t.Fatalf("expected a hash of the password, got %q", hash)
+	}
+	if HashPassword(password) == hash {
+		t.Errorf("expected the hashes of the same password to have different salts")
+	}
+	if !PasswordMatchesHash(password, hash) {
+		t.Errorf("expected the password to match its hash")
+	}
+	if PasswordMatchesHash("VMware123?", hash) {
+		t.Errorf("expected a different password not to match the hash")
+	}
+	if HashPasswordStateFunc(hash) != hash {
+		t.Errorf("expected a hash not to be hashed again")
+	}
+
+	// bcrypt accepts at most 72 bytes
+	longPassword := strings.Repeat("a", 100)
+	if !PasswordMatchesHash(longPassword, HashPassword(longPassword)) ||
+		PasswordMatchesHash(strings.Repeat("a", 99), HashPassword(longPassword)) {
+		t.Errorf("expected long passwords to be compared completely")
+	}
+
+	// states written before the passwords were hashed
+	if !PasswordMatchesHash(password, password) || PasswordMatchesHash("VMware123?", password) {
+		t.Errorf("expected a password in the state to be compared as is")
+	}
+}
@@ -5,6 +5,11 @@
 
 package resource_utils
 
+import (
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
 func ToBoolPointer(object interface{}) *bool {
 	if object == nil {
 		return nil
@@ -35,30 +40,117 @@ func CreateIdToObjectMap(objectsList []interface{}) map[string]interface{} {
 
 // CalculateAddedRemovedResources utility method that provides the newly created or removed
 // resources as a separate list, provided the new and old values of the resource list.
+// Resources are matched by ID, so both lists can be non-empty.
 func CalculateAddedRemovedResources(newResourcesList, oldResourcesList []interface{}) (
 	addedResources []map[string]interface{}, removedResources []map[string]interface{}) {
-	isAddingResources := len(newResourcesList) > len(oldResourcesList)
-	if isAddingResources {
-		oldResourcesMap := CreateIdToObjectMap(oldResourcesList)
-		for _, newHostListEntryRaw := range newResourcesList {
-			newResourceListEntry := newHostListEntryRaw.(map[string]interface{})
-			newHostEntryId := newResourceListEntry["id"].(string)
-			_, currentResourceAlreadyPresent := oldResourcesMap[newHostEntryId]
-			if !currentResourceAlreadyPresent {
-				addedResources = append(addedResources, newResourceListEntry)
-			}
+	oldResourcesMap := CreateIdToObjectMap(oldResourcesList)
+	for _, newResourceListEntryRaw := range newResourcesList {
+		newResourceListEntry := newResourceListEntryRaw.(map[string]interface{})
+		newResourceEntryId := newResourceListEntry["id"].(string)
+		_, currentResourceAlreadyPresent := oldResourcesMap[newResourceEntryId]
+		if !currentResourceAlreadyPresent {
+			addedResources = append(addedResources, newResourceListEntry)
 		}
-	} else {
-		newResourcesMap := CreateIdToObjectMap(newResourcesList)
-		for _, oldHostListEntryRaw := range oldResourcesList {
-			oldResourceListEntry := oldHostListEntryRaw.(map[string]interface{})
-			oldHostEntryId := oldResourceListEntry["id"].(string)
-			_, currentResourceAlreadyPresent := newResourcesMap[oldHostEntryId]
-			if !currentResourceAlreadyPresent {
-				removedResources = append(removedResources, oldResourceListEntry)
-			}
+	}
+	newResourcesMap := CreateIdToObjectMap(newResourcesList)
+	for _, oldResourceListEntryRaw := range oldResourcesList {
+		oldResourceListEntry := oldResourceListEntryRaw.(map[string]interface{})
+		oldResourceEntryId := oldResourceListEntry["id"].(string)
+		_, currentResourceStillPresent := newResourcesMap[oldResourceEntryId]
+		if !currentResourceStillPresent {
+			removedResources = append(removedResources, oldResourceListEntry)
 		}
 	}
 
 	return addedResources, removedResources
 }
+
+// CreateKeyToObjectMap Creates a Map with the value of the provided key as index to Object.
+func CreateKeyToObjectMap(objectsList []interface{}, key string) map[string]map[string]interface{} {
+	result := make(map[string]map[string]interface{})
+	for _, listEntryRaw := range objectsList {
+		listEntry := listEntryRaw.(map[string]interface{})
+		keyValue := listEntry[key].(string)
+		result[keyValue] = listEntry
+	}
+	return result
+}
+
+// HashByKey returns a schema.SchemaSetFunc that identifies the elements of a set only by the value
+// of the provided key, so that changing the order of the elements in the configuration produces no diff.
+// The SDK compares sets by the hashes of their elements, so changes to the other attributes of an
+// element produce no diff either. If several keys are provided, the value of the first non-empty one is used.
+func HashByKey(keys ...string) schema.SchemaSetFunc {
+	return func(v interface{}) int {
+		object := v.(map[string]interface{})
+		for _, key := range keys {
+			if keyValue, _ := object[key].(string); len(keyValue) > 0 {
+				return schema.HashString(keyValue)
+			}
+		}
+		return schema.HashString("")
+	}
+}
+
+// DeletionProtectionSchema the schema of the "deletion_protection" attribute, shared by all
+// resources whose deletion is destructive and can't be undone.
+func DeletionProtectionSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeBool,
+		Optional: true,
+		Default:  true,
+		Description: "Prevents the destruction of the resource. Must be set to false and applied " +
+			"before the resource can be destroyed",
+	}
+}
+
+// CheckDeletionProtection returns an error if deletion protection is enabled for the
+// resource with the provided type and ID.
+func CheckDeletionProtection(deletionProtection interface{}, resourceType, resourceId string) error {
+	if enabled, ok := deletionProtection.(bool); ok && enabled {
+		return fmt.Errorf("deletion protection is enabled for %s %q. Set \"deletion_protection\" "+
+			"to false and apply the configuration before destroying it", resourceType, resourceId)
+	}
+	return nil
+}
+
+// MergeWithStateByKey merges the refreshed objects with the objects in the state that have the same
+// value of the provided key. The attributes that are not refreshed, e.g. passwords and license keys
+// that the API does not return, are preserved from the state. Empty refreshed values do not
+// overwrite the ones in the state. The result has the order of refreshedList.
+func MergeWithStateByKey(stateList []interface{}, refreshedList []map[string]interface{},
+	key string) []map[string]interface{} {
+	stateMap := make(map[string]map[string]interface{}, len(stateList))
+	for _, stateEntryRaw := range stateList {
+		stateEntry, ok := stateEntryRaw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		keyValue, _ := stateEntry[key].(string)
+		stateMap[keyValue] = stateEntry
+	}
+
+	result := make([]map[string]interface{}, 0, len(refreshedList))
+	for _, refreshedEntry := range refreshedList {
+		keyValue, _ := refreshedEntry[key].(string)
+		result = append(result, MergeWithState(stateMap[keyValue], refreshedEntry))
+	}
+	return result
+}
+
+// MergeWithState creates a copy of the state object, updated with the non-empty refreshed values.
+func MergeWithState(stateEntry, refreshedEntry map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(stateEntry)+len(refreshedEntry))
+	for attributeName, value := range stateEntry {
+		result[attributeName] = value
+	}
+	for attributeName, value := range refreshedEntry {
+		if stringValue, isString := value.(string); isString && len(stringValue) == 0 {
+			if _, isInState := result[attributeName]; isInState {
+				continue
+			}
+		}
+		result[attributeName] = value
+	}
+	return result
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/vmware/vcf-sdk-go/client/clusters"
 	"github.com/vmware/vcf-sdk-go/client/domains"
+	"github.com/vmware/vcf-sdk-go/client/hosts"
 	"github.com/vmware/vcf-sdk-go/models"
 	"net/netip"
 	"strings"
@@ -119,6 +120,14 @@ func ConvertVcfErrorToDiag(err interface{}) diag.Diagnostics {
 	if ok {
 		return convertVcfErrorsToDiagErrors(createDomainBadRequest.Payload)
 	}
+	hostsBadRequest, ok := err.(*hosts.ValidateHostsOperationsBadRequest)
+	if ok {
+		return convertVcfErrorsToDiagErrors(hostsBadRequest.Payload)
+	}
+	commissionHostsBadRequest, ok := err.(*hosts.CommissionHostsBadRequest)
+	if ok {
+		return convertVcfErrorsToDiagErrors(commissionHostsBadRequest.Payload)
+	}
 
 	return diag.FromErr(err.(error))
 }
@@ -159,10 +168,17 @@ func ConvertValidationResultToDiag(validationResult *models.Validation) diag.Dia
 func convertValidationChecksToDiagErrors(validationChecks []*models.ValidationCheck) []diag.Diagnostic {
 	var result []diag.Diagnostic
 	for _, validationCheck := range validationChecks {
+		if validationCheck == nil {
+			continue
+		}
 		if validationCheck.Severity == "ERROR" {
+			summary := validationCheck.Description
+			if validationCheck.ErrorResponse != nil {
+				summary = validationCheck.ErrorResponse.Message
+			}
 			result = append(result, diag.Diagnostic{
 				Severity: diag.Error,
-				Summary:  validationCheck.ErrorResponse.Message,
+				Summary:  summary,
 				Detail:   validationCheck.Description,
 			})
 		}
@@ -173,6 +189,55 @@ func convertValidationChecksToDiagErrors(validationChecks []*models.ValidationCh
 	return result
 }
 
+// AssociateDiagsWithResources prefixes the summary of each diagnostic with the name of the resource
+// it concerns, e.g. the FQDN of a host, when exactly one of the provided resource names is mentioned
+// in the diagnostic. The other diagnostics are left unchanged.
+func AssociateDiagsWithResources(diags diag.Diagnostics, resourceNames []string) diag.Diagnostics {
+	var result diag.Diagnostics
+	for _, diagnostic := range diags {
+		var mentionedNames []string
+		for _, resourceName := range resourceNames {
+			if len(resourceName) > 0 && (mentionsHostName(diagnostic.Summary, resourceName) ||
+				mentionsHostName(diagnostic.Detail, resourceName)) {
+				mentionedNames = append(mentionedNames, resourceName)
+			}
+		}
+		if len(mentionedNames) == 1 {
+			diagnostic.Summary = fmt.Sprintf("%s: %s", mentionedNames[0], diagnostic.Summary)
+		}
+		result = append(result, diagnostic)
+	}
+	return result
+}
+
+// mentionsHostName returns whether the text contains the host name as a whole token, so that e.g.
+// "esxi-1.example.com" is not found in "esxi-11.example.com". Host names are compared case-insensitively
+// and may be followed by the period at the end of a sentence.
+func mentionsHostName(text, hostName string) bool {
+	text, hostName = strings.ToLower(text), strings.ToLower(hostName)
+	for offset := 0; offset < len(text); {
+		index := strings.Index(text[offset:], hostName)
+		if index < 0 {
+			return false
+		}
+		start, end := offset+index, offset+index+len(hostName)
+		if end < len(text) && text[end] == '.' {
+			// a period ends the host name only if no further label follows
+			end++
+		}
+		if (start == 0 || !isHostNameCharacter(text[start-1])) && (end == len(text) || !isHostNameCharacter(text[end])) {
+			return true
+		}
+		offset = start + 1
+	}
+	return false
+}
+
+func isHostNameCharacter(character byte) bool {
+	return (character >= 'a' && character <= 'z') || (character >= '0' && character <= '9') ||
+		character == '-' || character == '.'
+}
+
 func IsEmpty(object interface{}) bool {
 	if object == nil {
 		return true
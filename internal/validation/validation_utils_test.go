@@ -6,6 +6,7 @@
 package validation
 
 import (
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"reflect"
 	"strings"
 	"testing"
@@ -101,3 +102,56 @@ func TestValidateIpv4Address(t *testing.T) {
 		}
 	})
 }
+
+func TestAssociateDiagsWithResources(t *testing.T) {
+	diags := diag.Diagnostics{
+		{Severity: diag.Error, Summary: "Failed to connect", Detail: "Host esx01.vrack.vsphere.local is not reachable"},
+		{Severity: diag.Error, Summary: "Duplicate hosts esx01.vrack.vsphere.local, esx02.vrack.vsphere.local"},
+		{Severity: diag.Error, Summary: "Network pool not found"},
+	}
+	expectedSummaries := []string{
+		"esx01.vrack.vsphere.local: Failed to connect",
+		"Duplicate hosts esx01.vrack.vsphere.local, esx02.vrack.vsphere.local",
+		"Network pool not found",
+	}
+
+	result := AssociateDiagsWithResources(diags,
+		[]string{"esx01.vrack.vsphere.local", "esx02.vrack.vsphere.local"})
+	if len(result) != len(expectedSummaries) {
+		t.Fatalf("Failed. Expected %d diagnostics, got %d", len(expectedSummaries), len(result))
+	}
+	for i, diagnostic := range result {
+		if diagnostic.Summary != expectedSummaries[i] {
+			t.Errorf("Failed. Expected summary %q, got %q", expectedSummaries[i], diagnostic.Summary)
+		}
+	}
+}
+
+func TestMentionsHostName(t *testing.T) {
+	var mentionTests = []struct {
+		text              string
+		expectedMentioned bool
+	}{
+		{"Host esxi-1.example.com is not reachable", true},
+		{"Host ESXi-1.example.com is not reachable", true},
+		{"Failed to connect to esxi-1.example.com.", true},
+		{"esxi-1.example.com", true},
+		{"Host \"esxi-1.example.com\" not found", true},
+		{"Host esxi-11.example.com is not reachable", false},
+		{"Host new-esxi-1.example.com is not reachable", false},
+		{"Host esxi-1.example.com.au is not reachable", false},
+		{"Hosts esxi-11.example.com, esxi-1.example.com are not reachable", true},
+	}
+	for _, mentionTest := range mentionTests {
+		if mentionsHostName(mentionTest.text, "esxi-1.example.com") != mentionTest.expectedMentioned {
+			t.Errorf("Failed. Expected %q to mention the host: %t", mentionTest.text, mentionTest.expectedMentioned)
+		}
+	}
+
+	result := AssociateDiagsWithResources(diag.Diagnostics{
+		{Severity: diag.Error, Summary: "Failed to connect", Detail: "Host esxi-11.example.com is not reachable"},
+	}, []string{"esxi-1.example.com", "esxi-11.example.com"})
+	if result[0].Summary != "esxi-11.example.com: Failed to connect" {
+		t.Errorf("Failed. Expected the diagnostic to be associated with esxi-11.example.com, got %q", result[0].Summary)
+	}
+}
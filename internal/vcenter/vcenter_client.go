@@ -0,0 +1,365 @@
+/*
+ *  Copyright 2023 VMware, Inc.
+ *    SPDX-License-Identifier: MPL-2.0
+ */
+
+package vcenter
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"strings"
+	"time"
+)
+
+// vcenterTaskPollInterval the delay between two requests for the status of a vCenter task.
+const vcenterTaskPollInterval = 2 * time.Second
+
+// vimSoapAction the SOAP action of the vSphere Web Services API requests, the oldest API version of
+// the vCenter Server instances deployed by VCF.
+const vimSoapAction = "urn:vim25/7.0.0.0"
+
+// Client a minimal client of the vSphere Web Services API of vCenter Server, for the cluster
+// settings that the VCF API can't change on an existing cluster, i.e. the EVC mode and vSphere HA.
+type Client struct {
+	url            string
+	httpClient     *http.Client
+	serviceContent serviceContent
+}
+
+// ClusterSettings the settings of a vSphere cluster, that are managed in vCenter Server.
+type ClusterSettings struct {
+	// EvcMode the EVC mode of the cluster in the format of the VCF API, e.g. INTEL_SKYLAKE, or an empty
+	// string if EVC is disabled.
+	EvcMode string
+	// HighAvailabilityEnabled whether vSphere HA is enabled.
+	HighAvailabilityEnabled bool
+}
+
+type managedObjectReference struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+func (moRef managedObjectReference) toXml(elementName string) string {
+	return fmt.Sprintf("<%s type=%q>%s</%s>", elementName, moRef.Type, escapeXml(moRef.Value), elementName)
+}
+
+type serviceContent struct {
+	RootFolder        managedObjectReference `xml:"rootFolder"`
+	PropertyCollector managedObjectReference `xml:"propertyCollector"`
+	ViewManager       managedObjectReference `xml:"viewManager"`
+	SessionManager    managedObjectReference `xml:"sessionManager"`
+}
+
+// propertyValue the values of the retrieved properties, only the fields of the requested properties are set.
+type propertyValue struct {
+	Value             string `xml:",chardata"`
+	CurrentEVCModeKey string `xml:"currentEVCModeKey"`
+	DasConfig         struct {
+		Enabled bool `xml:"enabled"`
+	} `xml:"dasConfig"`
+	State string `xml:"state"`
+	Error struct {
+		LocalizedMessage string `xml:"localizedMessage"`
+	} `xml:"error"`
+}
+
+type objectContent struct {
+	Obj     managedObjectReference `xml:"obj"`
+	PropSet []struct {
+		Name string        `xml:"name"`
+		Val  propertyValue `xml:"val"`
+	} `xml:"propSet"`
+}
+
+type retrieveResult struct {
+	Token   string          `xml:"token"`
+	Objects []objectContent `xml:"objects"`
+}
+
+// NewClient creates a client of the vCenter Server instance with the provided FQDN and logs in.
+func NewClient(ctx context.Context, fqdn, username, password string, allowUnverifiedTls bool) (*Client, error) {
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, err
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: allowUnverifiedTls} // #nosec G402
+	client := &Client{
+		url:        fmt.Sprintf("https://%s/sdk", fqdn),
+		httpClient: &http.Client{Transport: transport, Jar: jar},
+	}
+	if err = client.login(ctx, username, password); err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+func (client *Client) login(ctx context.Context, username, password string) error {
+	var serviceContentResponse struct {
+		Returnval serviceContent `xml:"returnval"`
+	}
+	err := client.call(ctx, `<RetrieveServiceContent xmlns="urn:vim25">`+
+		`<_this type="ServiceInstance">ServiceInstance</_this></RetrieveServiceContent>`, &serviceContentResponse)
+	if err != nil {
+		return err
+	}
+	client.serviceContent = serviceContentResponse.Returnval
+
+	err = client.call(ctx, fmt.Sprintf(`<Login xmlns="urn:vim25">%s<userName>%s</userName><password>%s</password></Login>`,
+		client.serviceContent.SessionManager.toXml("_this"), escapeXml(username), escapeXml(password)), nil)
+	if err != nil {
+		return fmt.Errorf("failed to log in to vCenter Server %q: %w", client.url, err)
+	}
+	return nil
+}
+
+// Logout ends the session of the client.
+func (client *Client) Logout(ctx context.Context) {
+	_ = client.call(ctx, fmt.Sprintf(`<Logout xmlns="urn:vim25">%s</Logout>`,
+		client.serviceContent.SessionManager.toXml("_this")), nil)
+}
+
+// GetClusterSettings returns the EVC mode and the vSphere HA setting of the cluster with the provided name.
+func (client *Client) GetClusterSettings(ctx context.Context, clusterName string) (*ClusterSettings, error) {
+	clusterRef, err := client.findCluster(ctx, clusterName)
+	if err != nil {
+		return nil, err
+	}
+	objects, err := client.retrieveProperties(ctx, clusterRef, "summary", "configurationEx")
+	if err != nil {
+		return nil, err
+	}
+	result := &ClusterSettings{}
+	for _, object := range objects {
+		for _, property := range object.PropSet {
+			switch property.Name {
+			case "summary":
+				result.EvcMode = EvcModeFromKey(property.Val.CurrentEVCModeKey)
+			case "configurationEx":
+				result.HighAvailabilityEnabled = property.Val.DasConfig.Enabled
+			}
+		}
+	}
+	return result, nil
+}
+
+// SetClusterHighAvailability enables or disables vSphere HA on the cluster with the provided name.
+func (client *Client) SetClusterHighAvailability(ctx context.Context, clusterName string, enabled bool) error {
+	clusterRef, err := client.findCluster(ctx, clusterName)
+	if err != nil {
+		return err
+	}
+	var taskResponse struct {
+		Returnval managedObjectReference `xml:"returnval"`
+	}
+	err = client.call(ctx, fmt.Sprintf(`<ReconfigureComputeResource_Task xmlns="urn:vim25" `+
+		`xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance">%s<spec xsi:type="ClusterConfigSpecEx">`+
+		`<dasConfig><enabled>%t</enabled></dasConfig></spec><modify>true</modify></ReconfigureComputeResource_Task>`,
+		clusterRef.toXml("_this"), enabled), &taskResponse)
+	if err != nil {
+		return err
+	}
+	return client.waitForTask(ctx, taskResponse.Returnval)
+}
+
+// SetClusterEvcMode configures the EVC mode, in the format of the VCF API, of the cluster with the
+// provided name. EVC is disabled if the mode is empty.
+func (client *Client) SetClusterEvcMode(ctx context.Context, clusterName, evcMode string) error {
+	clusterRef, err := client.findCluster(ctx, clusterName)
+	if err != nil {
+		return err
+	}
+	var evcManagerResponse struct {
+		Returnval managedObjectReference `xml:"returnval"`
+	}
+	err = client.call(ctx, fmt.Sprintf(`<EvcManager xmlns="urn:vim25">%s</EvcManager>`, clusterRef.toXml("_this")),
+		&evcManagerResponse)
+	if err != nil {
+		return err
+	}
+
+	var request string
+	if len(evcMode) == 0 {
+		request = fmt.Sprintf(`<DisableEvcMode_Task xmlns="urn:vim25">%s</DisableEvcMode_Task>`,
+			evcManagerResponse.Returnval.toXml("_this"))
+	} else {
+		request = fmt.Sprintf(`<ConfigureEvcMode_Task xmlns="urn:vim25">%s<evcModeKey>%s</evcModeKey></ConfigureEvcMode_Task>`,
+			evcManagerResponse.Returnval.toXml("_this"), escapeXml(EvcModeToKey(evcMode)))
+	}
+	var taskResponse struct {
+		Returnval managedObjectReference `xml:"returnval"`
+	}
+	if err = client.call(ctx, request, &taskResponse); err != nil {
+		return err
+	}
+	return client.waitForTask(ctx, taskResponse.Returnval)
+}
+
+// findCluster returns the reference of the cluster with the provided name, searched in the whole inventory.
+func (client *Client) findCluster(ctx context.Context, clusterName string) (managedObjectReference, error) {
+	var viewResponse struct {
+		Returnval managedObjectReference `xml:"returnval"`
+	}
+	err := client.call(ctx, fmt.Sprintf(`<CreateContainerView xmlns="urn:vim25">%s%s`+
+		`<type>ClusterComputeResource</type><recursive>true</recursive></CreateContainerView>`,
+		client.serviceContent.ViewManager.toXml("_this"), client.serviceContent.RootFolder.toXml("container")),
+		&viewResponse)
+	if err != nil {
+		return managedObjectReference{}, err
+	}
+	view := viewResponse.Returnval
+	defer func() {
+		_ = client.call(ctx, fmt.Sprintf(`<DestroyView xmlns="urn:vim25">%s</DestroyView>`, view.toXml("_this")), nil)
+	}()
+
+	objects, err := client.retrieve(ctx, fmt.Sprintf(`<propSet><type>ClusterComputeResource</type><pathSet>name</pathSet></propSet>`+
+		`<objectSet>%s<skip>true</skip><selectSet xsi:type="TraversalSpec"><name>traverseView</name>`+
+		`<type>ContainerView</type><path>view</path><skip>false</skip></selectSet></objectSet>`, view.toXml("obj")))
+	if err != nil {
+		return managedObjectReference{}, err
+	}
+	for _, object := range objects {
+		for _, property := range object.PropSet {
+			if property.Name == "name" && property.Val.Value == clusterName {
+				return object.Obj, nil
+			}
+		}
+	}
+	return managedObjectReference{}, fmt.Errorf("cluster %q not found in vCenter Server %q", clusterName, client.url)
+}
+
+func (client *Client) retrieveProperties(ctx context.Context, objectRef managedObjectReference,
+	propertyPaths ...string) ([]objectContent, error) {
+	var pathSet strings.Builder
+	for _, propertyPath := range propertyPaths {
+		pathSet.WriteString(fmt.Sprintf("<pathSet>%s</pathSet>", escapeXml(propertyPath)))
+	}
+	return client.retrieve(ctx, fmt.Sprintf(`<propSet><type>%s</type>%s</propSet><objectSet>%s<skip>false</skip></objectSet>`,
+		objectRef.Type, pathSet.String(), objectRef.toXml("obj")))
+}
+
+// retrieve retrieves the properties selected by the provided property filter spec, following the pages of the result.
+func (client *Client) retrieve(ctx context.Context, filterSpec string) ([]objectContent, error) {
+	var retrieveResponse struct {
+		Returnval retrieveResult `xml:"returnval"`
+	}
+	err := client.call(ctx, fmt.Sprintf(`<RetrievePropertiesEx xmlns="urn:vim25" `+
+		`xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance">%s<specSet>%s</specSet><options></options></RetrievePropertiesEx>`,
+		client.serviceContent.PropertyCollector.toXml("_this"), filterSpec), &retrieveResponse)
+	if err != nil {
+		return nil, err
+	}
+	result := retrieveResponse.Returnval.Objects
+	for token := retrieveResponse.Returnval.Token; len(token) > 0; token = retrieveResponse.Returnval.Token {
+		retrieveResponse.Returnval = retrieveResult{}
+		err = client.call(ctx, fmt.Sprintf(`<ContinueRetrievePropertiesEx xmlns="urn:vim25">%s<token>%s</token>`+
+			`</ContinueRetrievePropertiesEx>`, client.serviceContent.PropertyCollector.toXml("_this"), escapeXml(token)),
+			&retrieveResponse)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, retrieveResponse.Returnval.Objects...)
+	}
+	return result, nil
+}
+
+// waitForTask waits until the vCenter task completes and returns its error, if it fails.
+func (client *Client) waitForTask(ctx context.Context, taskRef managedObjectReference) error {
+	for {
+		objects, err := client.retrieveProperties(ctx, taskRef, "info")
+		if err != nil {
+			return err
+		}
+		for _, object := range objects {
+			for _, property := range object.PropSet {
+				switch property.Val.State {
+				case "success":
+					return nil
+				case "error":
+					return fmt.Errorf("vCenter task %q failed: %s", taskRef.Value, property.Val.Error.LocalizedMessage)
+				}
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(vcenterTaskPollInterval):
+		}
+	}
+}
+
+// call sends a request to the vSphere Web Services API and decodes the response element into response,
+// if it is not nil. SOAP faults are returned as errors.
+func (client *Client) call(ctx context.Context, requestBody string, response interface{}) error {
+	envelope := `<?xml version="1.0" encoding="UTF-8"?><soapenv:Envelope ` +
+		`xmlns:soapenv="http://schemas.xmlsoap.org/soap/envelope/"><soapenv:Body>` + requestBody +
+		`</soapenv:Body></soapenv:Envelope>`
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, client.url, strings.NewReader(envelope))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "text/xml; charset=utf-8")
+	request.Header.Set("SOAPAction", vimSoapAction)
+
+	httpResponse, err := client.httpClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer httpResponse.Body.Close()
+	responseBytes, err := io.ReadAll(httpResponse.Body)
+	if err != nil {
+		return err
+	}
+
+	var responseEnvelope struct {
+		Body struct {
+			Fault *struct {
+				FaultString string `xml:"faultstring"`
+			} `xml:"Fault"`
+			Content []byte `xml:",innerxml"`
+		} `xml:"Body"`
+	}
+	if err = xml.Unmarshal(responseBytes, &responseEnvelope); err != nil {
+		return fmt.Errorf("failed to decode the response of vCenter Server %q (HTTP %d): %w",
+			client.url, httpResponse.StatusCode, err)
+	}
+	if responseEnvelope.Body.Fault != nil {
+		return fmt.Errorf("vCenter Server %q returned an error: %s", client.url, responseEnvelope.Body.Fault.FaultString)
+	}
+	if response == nil {
+		return nil
+	}
+	return xml.Unmarshal(bytes.TrimSpace(responseEnvelope.Body.Content), response)
+}
+
+// EvcModeToKey converts an EVC mode of the VCF API, e.g. INTEL_SKYLAKE, to the EVC mode key of vCenter
+// Server, e.g. intel-skylake.
+func EvcModeToKey(evcMode string) string {
+	if strings.ToUpper(evcMode) == "INTEL_NEALEM" {
+		// the VCF API misspells the Nehalem generation
+		return "intel-nehalem"
+	}
+	return strings.ReplaceAll(strings.ToLower(evcMode), "_", "-")
+}
+
+// EvcModeFromKey converts an EVC mode key of vCenter Server to the EVC mode of the VCF API.
+func EvcModeFromKey(evcModeKey string) string {
+	if evcModeKey == "intel-nehalem" {
+		return "INTEL_NEALEM"
+	}
+	return strings.ReplaceAll(strings.ToUpper(evcModeKey), "-", "_")
+}
+
+func escapeXml(value string) string {
+	var buf bytes.Buffer
+	_ = xml.EscapeText(&buf, []byte(value))
+	return buf.String()
+}
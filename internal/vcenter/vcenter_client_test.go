@@ -0,0 +1,135 @@
+/*
+ *  Copyright 2023 VMware, Inc.
+ *    SPDX-License-Identifier: MPL-2.0
+ */
+
+package vcenter
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// fakeVcenterResponses the bodies of the responses of a fake vCenter Server, by the operation of the request
+var fakeVcenterResponses = map[string]string{
+	"RetrieveServiceContent": `<RetrieveServiceContentResponse xmlns="urn:vim25"><returnval>` +
+		`<rootFolder type="Folder">group-d1</rootFolder>` +
+		`<propertyCollector type="PropertyCollector">propertyCollector</propertyCollector>` +
+		`<viewManager type="ViewManager">ViewManager</viewManager>` +
+		`<sessionManager type="SessionManager">SessionManager</sessionManager>` +
+		`</returnval></RetrieveServiceContentResponse>`,
+	"Login":               `<LoginResponse xmlns="urn:vim25"><returnval></returnval></LoginResponse>`,
+	"Logout":              `<LogoutResponse xmlns="urn:vim25"></LogoutResponse>`,
+	"CreateContainerView": `<CreateContainerViewResponse xmlns="urn:vim25"><returnval type="ContainerView">session[1]view</returnval></CreateContainerViewResponse>`,
+	"DestroyView":         `<DestroyViewResponse xmlns="urn:vim25"></DestroyViewResponse>`,
+	"ContainerView": `<RetrievePropertiesExResponse xmlns="urn:vim25"><returnval><token>1</token>` +
+		`<objects><obj type="ClusterComputeResource">domain-c1</obj><propSet><name>name</name><val xsi:type="xsd:string">sfo-m01-cl01</val></propSet></objects>` +
+		`</returnval></RetrievePropertiesExResponse>`,
+	"ContinueRetrievePropertiesEx": `<ContinueRetrievePropertiesExResponse xmlns="urn:vim25"><returnval>` +
+		`<objects><obj type="ClusterComputeResource">domain-c8</obj><propSet><name>name</name><val xsi:type="xsd:string">sfo-w01-cl01</val></propSet></objects>` +
+		`</returnval></ContinueRetrievePropertiesExResponse>`,
+	"ClusterComputeResource": `<RetrievePropertiesExResponse xmlns="urn:vim25"><returnval><objects><obj type="ClusterComputeResource">domain-c8</obj>` +
+		`<propSet><name>configurationEx</name><val xsi:type="ClusterConfigInfoEx"><dasConfig><enabled>true</enabled></dasConfig></val></propSet>` +
+		`<propSet><name>summary</name><val xsi:type="ClusterComputeResourceSummary"><currentEVCModeKey>intel-nehalem</currentEVCModeKey></val></propSet>` +
+		`</objects></returnval></RetrievePropertiesExResponse>`,
+	"ReconfigureComputeResource_Task": `<ReconfigureComputeResource_TaskResponse xmlns="urn:vim25"><returnval type="Task">task-1</returnval></ReconfigureComputeResource_TaskResponse>`,
+	"Task": `<RetrievePropertiesExResponse xmlns="urn:vim25"><returnval><objects><obj type="Task">task-1</obj>` +
+		`<propSet><name>info</name><val xsi:type="TaskInfo"><state>error</state><error><localizedMessage>Insufficient resources</localizedMessage></error></val></propSet>` +
+		`</objects></returnval></RetrievePropertiesExResponse>`,
+}
+
+func newFakeVcenterServer(t *testing.T, requests *[]string) *httptest.Server {
+	return httptest.NewTLSServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		requestBytes, _ := io.ReadAll(request.Body)
+		requestBody := string(requestBytes)
+		*requests = append(*requests, requestBody)
+
+		operation := strings.SplitN(strings.SplitN(requestBody, "<soapenv:Body><", 2)[1], " ", 2)[0]
+		if operation == "RetrievePropertiesEx" {
+			// the responses of the property collector depend on the type of the retrieved object
+			operation = strings.SplitN(strings.SplitN(requestBody, "<objectSet><obj type=\"", 2)[1], "\"", 2)[0]
+		}
+		responseBody, ok := fakeVcenterResponses[operation]
+		if !ok {
+			t.Errorf("unexpected request %s", requestBody)
+		}
+		if operation == "Login" && !strings.Contains(requestBody, "<password>VMware1!&amp;</password>") {
+			writer.WriteHeader(http.StatusInternalServerError)
+			responseBody = `<soapenv:Fault><faultcode>ServerFaultCode</faultcode><faultstring>Cannot complete login</faultstring></soapenv:Fault>`
+		}
+		_, _ = fmt.Fprintf(writer, `<?xml version="1.0" encoding="UTF-8"?><soapenv:Envelope xmlns:soapenv="http://schemas.xmlsoap.org/soap/envelope/" `+
+			`xmlns:xsd="http://www.w3.org/2001/XMLSchema" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance"><soapenv:Body>%s</soapenv:Body></soapenv:Envelope>`,
+			responseBody)
+	}))
+}
+
+func TestClient(t *testing.T) {
+	var requests []string
+	server := newFakeVcenterServer(t, &requests)
+	defer server.Close()
+	fqdn := strings.TrimPrefix(server.URL, "https://")
+	ctx := context.Background()
+
+	_, err := NewClient(ctx, fqdn, "administrator@vsphere.local", "wrong", true)
+	if err == nil || !strings.Contains(err.Error(), "Cannot complete login") {
+		t.Errorf("expected a login error, got %v", err)
+	}
+
+	client, err := NewClient(ctx, fqdn, "administrator@vsphere.local", "VMware1!&", true)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	clusterSettings, err := client.GetClusterSettings(ctx, "sfo-w01-cl01")
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if clusterSettings.EvcMode != "INTEL_NEALEM" || !clusterSettings.HighAvailabilityEnabled {
+		t.Errorf("unexpected cluster settings %+v", *clusterSettings)
+	}
+	if !strings.Contains(requests[len(requests)-1], `<obj type="ClusterComputeResource">domain-c8</obj>`) {
+		t.Errorf("expected the settings of the cluster on the second page to be retrieved, got %s", requests[len(requests)-1])
+	}
+
+	_, err = client.GetClusterSettings(ctx, "sfo-w02-cl01")
+	if err == nil || !strings.Contains(err.Error(), "\"sfo-w02-cl01\" not found") {
+		t.Errorf("expected an error about the unknown cluster, got %v", err)
+	}
+
+	err = client.SetClusterHighAvailability(ctx, "sfo-w01-cl01", false)
+	if err == nil || !strings.Contains(err.Error(), "Insufficient resources") {
+		t.Errorf("expected the error of the task, got %v", err)
+	}
+	for _, request := range requests {
+		if strings.Contains(request, "<ReconfigureComputeResource_Task") &&
+			!strings.Contains(request, "<dasConfig><enabled>false</enabled></dasConfig></spec><modify>true</modify>") {
+			t.Errorf("unexpected reconfiguration request %s", request)
+		}
+	}
+	client.Logout(ctx)
+}
+
+var evcModeTests = []struct {
+	evcMode    string
+	evcModeKey string
+}{
+	{"INTEL_SKYLAKE", "intel-skylake"},
+	{"AMD_GREYHOUND_NO3DNOW", "amd-greyhound-no3dnow"},
+	{"INTEL_NEALEM", "intel-nehalem"},
+	{"", ""},
+}
+
+func TestEvcModeKeys(t *testing.T) {
+	for _, evcModeTest := range evcModeTests {
+		if key := EvcModeToKey(evcModeTest.evcMode); key != evcModeTest.evcModeKey {
+			t.Errorf("EvcModeToKey(%q): expected %q, got %q", evcModeTest.evcMode, evcModeTest.evcModeKey, key)
+		}
+		if mode := EvcModeFromKey(evcModeTest.evcModeKey); mode != evcModeTest.evcMode {
+			t.Errorf("EvcModeFromKey(%q): expected %q, got %q", evcModeTest.evcModeKey, evcModeTest.evcMode, mode)
+		}
+	}
+}